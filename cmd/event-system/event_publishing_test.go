@@ -2,15 +2,19 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"event-system/internal/application"
 	"event-system/internal/domain"
 	"event-system/internal/infrastructure"
 	iface "event-system/internal/interface"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -29,6 +33,301 @@ func TestEventPublishingIntegration(t *testing.T) {
 	t.Logf("✅ Integration test passed: OrderStatusEvent published successfully")
 }
 
+func TestEventPublishingAssignsIDAndTimestamp(t *testing.T) {
+	mockPublisher, eventHandler := setupEventSystem(t)
+
+	body := `{"type": "OrderStatusEvent", "payload": {"orderId": "order-1", "status": "confirmed"}}`
+	w := httptest.NewRecorder()
+	eventHandler.HandleEvent(w, httptest.NewRequest("POST", "/event", strings.NewReader(body)))
+
+	assertSuccessfulResponse(t, w)
+	var receipt struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &receipt); err != nil || receipt.ID == "" {
+		t.Fatalf("expected response with assigned event ID, got %s", w.Body.String())
+	}
+	published := mockPublisher.PublishedEvents[0].Event
+	if published.ID != receipt.ID || published.Timestamp.IsZero() {
+		t.Errorf("expected published event with ID %s and timestamp, got %q at %v", receipt.ID, published.ID, published.Timestamp)
+	}
+}
+
+func TestEventPublishingMetadataFromHeaders(t *testing.T) {
+	mockPublisher, eventHandler := setupEventSystem(t)
+
+	body := `{"type": "OrderStatusEvent", "payload": {"orderId": "order-1", "status": "confirmed"},
+		"metadata": {"correlation_id": "corr-from-body", "headers": {"origin": "body"}}}`
+	req := httptest.NewRequest("POST", "/event", strings.NewReader(body))
+	req.Header.Set("X-Correlation-ID", "corr-from-header")
+	req.Header.Set("X-Causation-ID", "evt-0")
+	req.Header.Set("X-Tenant-ID", "acme")
+	req.Header.Set("X-Meta-Region", "eu")
+	w := httptest.NewRecorder()
+	eventHandler.HandleEvent(w, req)
+
+	assertSuccessfulResponse(t, w)
+	m := mockPublisher.PublishedEvents[0].Event.Metadata
+	if m == nil {
+		t.Fatal("expected metadata on published event")
+	}
+	if m.CorrelationID != "corr-from-body" || m.CausationID != "evt-0" || m.Tenant != "acme" {
+		t.Errorf("unexpected metadata fields: %+v", m)
+	}
+	if m.Headers["origin"] != "body" || m.Headers["region"] != "eu" {
+		t.Errorf("unexpected metadata headers: %v", m.Headers)
+	}
+}
+
+func TestEventPublishingCloudEvents(t *testing.T) {
+	mockPublisher, eventHandler := setupEventSystem(t)
+
+	// Structured mode: the whole CloudEvent in the body
+	structured := `{"specversion": "1.0", "id": "ce-1", "source": "/billing", "type": "OrderStatusEvent",
+		"time": "2024-05-01T12:00:00Z", "tenant": "acme", "data": {"orderId": "order-1", "status": "confirmed"}}`
+	req := httptest.NewRequest("POST", "/event", strings.NewReader(structured))
+	req.Header.Set("Content-Type", "application/cloudevents+json; charset=utf-8")
+	w := httptest.NewRecorder()
+	eventHandler.HandleEvent(w, req)
+	assertSuccessfulResponse(t, w)
+
+	// Binary mode: attributes in Ce-* headers, data in the body
+	req = httptest.NewRequest("POST", "/event", strings.NewReader(`{"orderId": "order-2", "status": "shipped"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Ce-Specversion", "1.0")
+	req.Header.Set("Ce-Id", "ce-2")
+	req.Header.Set("Ce-Source", "/warehouse")
+	req.Header.Set("Ce-Type", "OrderStatusEvent")
+	req.Header.Set("Ce-Correlationid", "corr-2")
+	w = httptest.NewRecorder()
+	eventHandler.HandleEvent(w, req)
+	assertSuccessfulResponse(t, w)
+
+	if len(mockPublisher.PublishedEvents) != 2 {
+		t.Fatalf("expected 2 published events, got %d", len(mockPublisher.PublishedEvents))
+	}
+	first, second := mockPublisher.PublishedEvents[0].Event, mockPublisher.PublishedEvents[1].Event
+	if first.ID != "ce-1" || first.Payload["orderId"] != "order-1" || first.Metadata.Source != "/billing" || first.Metadata.Tenant != "acme" {
+		t.Errorf("unexpected structured event: %+v, metadata %+v", first, first.Metadata)
+	}
+	if second.ID != "ce-2" || second.Payload["status"] != "shipped" || second.Metadata.CorrelationID != "corr-2" || second.Timestamp.IsZero() {
+		t.Errorf("unexpected binary event: %+v, metadata %+v", second, second.Metadata)
+	}
+
+	// Missing required attribute
+	req = httptest.NewRequest("POST", "/event", strings.NewReader(`{"orderId": "order-3", "status": "shipped"}`))
+	req.Header.Set("Ce-Specversion", "1.0")
+	req.Header.Set("Ce-Type", "OrderStatusEvent")
+	w = httptest.NewRecorder()
+	eventHandler.HandleEvent(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for CloudEvent without id and source, got %d", w.Code)
+	}
+}
+
+func TestEventPublishingValidationProblem(t *testing.T) {
+	mockPublisher, eventHandler := setupEventSystem(t)
+
+	body := `{"type": "OrderStatusEvent", "payload": {"status": "lost"}}`
+	w := httptest.NewRecorder()
+	eventHandler.HandleEvent(w, httptest.NewRequest("POST", "/event", strings.NewReader(body)))
+
+	if w.Code != http.StatusBadRequest || w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Fatalf("expected 400 problem+json, got %d %s: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	var problem iface.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if problem.Status != http.StatusBadRequest || problem.Instance != "/event" || problem.Title == "" {
+		t.Errorf("unexpected problem: %+v", problem)
+	}
+	rules := make(map[string]string)
+	for _, v := range problem.Violations {
+		rules[v.Pointer] = v.Rule
+	}
+	if rules["/payload/orderId"] != "required" || rules["/payload/status"] != "enum" {
+		t.Errorf("expected violations for orderId and status, got %+v", problem.Violations)
+	}
+	if len(mockPublisher.PublishedEvents) != 0 {
+		t.Error("invalid event must not be published")
+	}
+}
+
+func TestEventPublishingErrorCodes(t *testing.T) {
+	cases := []struct {
+		name       string
+		body       string
+		publishErr error
+		status     int
+		code       string
+	}{
+		{"unknown type", `{"type": "RefundEvent", "payload": {}}`, nil, http.StatusNotFound, "unknown-event-type"},
+		{"invalid payload", orderStatusEventJSON("evt-1", "order-1", "lost"), nil, http.StatusBadRequest, "validation-failed"},
+		{"transient publish failure", orderStatusEventJSON("evt-2", "order-1", "confirmed"), errors.New("broker unavailable"), http.StatusServiceUnavailable, "publish-unavailable"},
+		{"permanent publish failure", orderStatusEventJSON("evt-3", "order-1", "confirmed"), fmt.Errorf("%w: status 400", infrastructure.ErrPermanentDelivery), http.StatusBadGateway, "publish-rejected"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPublisher, eventHandler := setupEventSystem(t)
+			mockPublisher.Err = tc.publishErr
+
+			w := httptest.NewRecorder()
+			eventHandler.HandleEvent(w, httptest.NewRequest("POST", "/event", strings.NewReader(tc.body)))
+
+			var problem iface.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to decode problem: %v", err)
+			}
+			if w.Code != tc.status || problem.Status != tc.status || problem.Code != tc.code {
+				t.Errorf("expected %d %s, got %d %+v", tc.status, tc.code, w.Code, problem)
+			}
+		})
+	}
+}
+
+func TestEventPublishingIdempotencyKey(t *testing.T) {
+	mockPublisher, eventHandler := setupEventSystem(t)
+	eventHandler.Service.Idempotency = infrastructure.NewMemoryIdempotencyStore(infrastructure.IdempotencyConfig{})
+
+	send := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/event", strings.NewReader(orderStatusEventJSON(id, "order-1", "confirmed")))
+		req.Header.Set("Idempotency-Key", "mobile-retry-1")
+		w := httptest.NewRecorder()
+		eventHandler.HandleEvent(w, req)
+		return w
+	}
+
+	first := send("evt-1")
+	retry := send("evt-2")
+
+	assertSuccessfulResponse(t, first)
+	assertSuccessfulResponse(t, retry)
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected retry to be marked as replayed")
+	}
+	if len(mockPublisher.PublishedEvents) != 1 {
+		t.Errorf("expected retry not to be published again, got %d events", len(mockPublisher.PublishedEvents))
+	}
+}
+
+func TestBatchEventPublishingIntegration(t *testing.T) {
+	registry := createTestEventRegistry(t)
+	mockPublisher := &MockTransactionalPublisher{}
+	batchHandler := iface.NewBatchEventHandler(application.NewEventService(createTestValidator(t, registry), mockPublisher))
+
+	// NDJSON: valid, invalid status, malformed line
+	body := orderStatusEventJSON("evt-1", "order-1", "confirmed") + "\n" +
+		orderStatusEventJSON("evt-2", "order-2", "lost") + "\n" +
+		"{not json}\n"
+	partial := sendBatch(t, batchHandler, "partial", body)
+	if partial.Code != http.StatusMultiStatus {
+		t.Fatalf("expected status 207, got %d. Body: %s", partial.Code, partial.Body.String())
+	}
+	resp := decodeBatchResponse(t, partial)
+	if resp.Accepted != 1 || resp.Rejected != 2 || resp.Results[0].EventID != "evt-1" || resp.Results[2].Index != 2 {
+		t.Errorf("unexpected partial batch response: %+v", resp)
+	}
+	if len(mockPublisher.PublishedEvents) != 1 {
+		t.Fatalf("expected 1 published event, got %d", len(mockPublisher.PublishedEvents))
+	}
+
+	// JSON array in atomic mode: one invalid event rejects the batch
+	array := "[" + orderStatusEventJSON("evt-3", "order-3", "shipped") + "," + orderStatusEventJSON("evt-4", "order-4", "lost") + "]"
+	atomic := sendBatch(t, batchHandler, "atomic", array)
+	if atomic.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d. Body: %s", atomic.Code, atomic.Body.String())
+	}
+	if resp := decodeBatchResponse(t, atomic); resp.Skipped != 1 || resp.Rejected != 1 {
+		t.Errorf("unexpected atomic batch response: %+v", resp)
+	}
+	if len(mockPublisher.PublishedEvents) != 1 {
+		t.Errorf("atomic batch must not publish, got %d published events", len(mockPublisher.PublishedEvents))
+	}
+}
+
+func TestBatchEventPublishingAtomicRequiresTransactions(t *testing.T) {
+	registry := createTestEventRegistry(t)
+	mockPublisher := &MockPublisher{}
+	batchHandler := iface.NewBatchEventHandler(application.NewEventService(createTestValidator(t, registry), mockPublisher))
+
+	array := "[" + orderStatusEventJSON("evt-1", "order-1", "shipped") + "," + orderStatusEventJSON("evt-2", "order-2", "packed") + "]"
+	w := sendBatch(t, batchHandler, "atomic", array)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status 422, got %d. Body: %s", w.Code, w.Body.String())
+	}
+	var problem iface.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil || problem.Code != "atomic-unsupported" {
+		t.Errorf("expected atomic-unsupported problem, got %+v (%v)", problem, err)
+	}
+	if !strings.Contains(problem.Detail, "EVENT_OUTBOX_DSN") {
+		t.Errorf("expected the problem detail to name the outbox setting, got %q", problem.Detail)
+	}
+	if len(mockPublisher.PublishedEvents) != 0 {
+		t.Errorf("expected nothing published, got %d events", len(mockPublisher.PublishedEvents))
+	}
+}
+
+func TestDeadLetterReplayProblems(t *testing.T) {
+	registry := createTestEventRegistry(t)
+	mockPublisher := &MockPublisher{}
+	service := application.NewEventService(createTestValidator(t, registry), mockPublisher)
+	store, err := infrastructure.NewSQLiteDeadLetterStore(":memory:", registry)
+	if err != nil {
+		t.Fatalf("failed to open dead letter store: %v", err)
+	}
+	defer store.Close()
+
+	event := domain.NewEvent("OrderStatusEvent", map[string]interface{}{"orderId": "order-1", "status": "confirmed"})
+	if err := store.Add(context.Background(), event, domain.DeadLetterStagePublish, errors.New("broker down")); err != nil {
+		t.Fatalf("failed to add dead letter: %v", err)
+	}
+	letters, err := store.List("order-dlq", false)
+	if err != nil || len(letters) != 1 {
+		t.Fatalf("expected one dead letter, got %d (%v)", len(letters), err)
+	}
+	id := letters[0].ID
+
+	mux := http.NewServeMux()
+	handler := iface.NewDeadLetterHandler(store, service)
+	mux.HandleFunc("GET /admin/dead-letters/{id}", handler.GetDeadLetter)
+	mux.HandleFunc("POST /admin/dead-letters/{id}/replay", handler.ReplayDeadLetter)
+
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		publishErr error
+		status     int
+		code       string
+	}{
+		{"unknown dead letter", "GET", "/admin/dead-letters/missing", "", nil, http.StatusNotFound, "dead-letter-not-found"},
+		{"invalid JSON", "POST", "/admin/dead-letters/" + id + "/replay", "{", nil, http.StatusBadRequest, "invalid-event"},
+		{"invalid payload", "POST", "/admin/dead-letters/" + id + "/replay", orderStatusEventJSON("evt-1", "order-1", "lost"), nil, http.StatusBadRequest, "validation-failed"},
+		{"transient publish failure", "POST", "/admin/dead-letters/" + id + "/replay", "", errors.New("broker unavailable"), http.StatusServiceUnavailable, "publish-unavailable"},
+		{"permanent publish failure", "POST", "/admin/dead-letters/" + id + "/replay", "", fmt.Errorf("%w: status 400", infrastructure.ErrPermanentDelivery), http.StatusBadGateway, "publish-rejected"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPublisher.Err = tc.publishErr
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+			assertProblem(t, w, tc.status, tc.code)
+		})
+	}
+
+	mockPublisher.Err = nil
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/admin/dead-letters/"+id+"/replay", nil))
+	assertSuccessfulResponse(t, w)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/admin/dead-letters/"+id+"/replay", nil))
+	assertProblem(t, w, http.StatusConflict, "already-replayed")
+}
+
 // === Test Helpers ===
 
 func setupEventSystem(t *testing.T) (*MockPublisher, *iface.EventHandler) {
@@ -62,6 +361,45 @@ func sendOrderStatusEvent(t *testing.T, handler *iface.EventHandler, orderId, st
 	return w
 }
 
+func orderStatusEventJSON(id, orderId, status string) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"id":        id,
+		"type":      "OrderStatusEvent",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"payload":   map[string]interface{}{"orderId": orderId, "status": status},
+	})
+	return string(data)
+}
+
+func sendBatch(t *testing.T, handler *iface.BatchEventHandler, mode, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/events/batch?mode="+mode, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.HandleBatch(w, req)
+	return w
+}
+
+func decodeBatchResponse(t *testing.T, w *httptest.ResponseRecorder) iface.BatchResponse {
+	var resp iface.BatchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode batch response: %v", err)
+	}
+	return resp
+}
+
+func assertProblem(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); ct != iface.ProblemContentType {
+		t.Errorf("expected %s, got %q", iface.ProblemContentType, ct)
+	}
+	var problem iface.Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	if w.Code != status || problem.Status != status || problem.Code != code {
+		t.Errorf("expected %d %s, got %d %+v", status, code, w.Code, problem)
+	}
+}
+
 func assertSuccessfulResponse(t *testing.T, w *httptest.ResponseRecorder) {
 	if w.Code != http.StatusOK {
 		t.Fatalf("expected status 200, got %d. Body: %s", w.Code, w.Body.String())
@@ -96,9 +434,10 @@ func createTestEventRegistry(t *testing.T) *infrastructure.EventRegistry {
 
 	channelsConfig := map[string]any{
 		"OrderStatusEvent": map[string]string{
-			"endpoint": "order-topic",
-			"schema":   "order_status_notification_test",
-			"type":     "kafka",
+			"endpoint":    "order-topic",
+			"schema":      "order_status_notification_test",
+			"type":        "kafka",
+			"dead_letter": "order-dlq",
 		},
 	}
 
@@ -160,6 +499,22 @@ func createTestValidator(t *testing.T, registry *infrastructure.EventRegistry) *
 
 type MockPublisher struct {
 	PublishedEvents []PublishedEvent
+	Err             error // returned instead of publishing when set
+}
+
+// MockTransactionalPublisher publishes a batch all-or-nothing, like the outbox.
+type MockTransactionalPublisher struct {
+	MockPublisher
+}
+
+func (m *MockTransactionalPublisher) PublishAll(ctx context.Context, events []*domain.Event) error {
+	if m.Err != nil {
+		return m.Err
+	}
+	for _, event := range events {
+		m.PublishedEvents = append(m.PublishedEvents, PublishedEvent{Event: event})
+	}
+	return nil
 }
 
 type PublishedEvent struct {
@@ -167,7 +522,10 @@ type PublishedEvent struct {
 	Topic string
 }
 
-func (m *MockPublisher) Publish(event *domain.Event) error {
+func (m *MockPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	if m.Err != nil {
+		return m.Err
+	}
 	m.PublishedEvents = append(m.PublishedEvents, PublishedEvent{
 		Event: event,
 		Topic: "", // TODO: get from EventRegistry
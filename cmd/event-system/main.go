@@ -1,66 +1,232 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"event-system/internal/application"
 	"event-system/internal/domain"
 	"event-system/internal/infrastructure"
 	iface "event-system/internal/interface"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 type fakePublisher struct{}
 
-func (f *fakePublisher) Publish(*domain.Event) error {
+func (f *fakePublisher) Publish(context.Context, *domain.Event) error {
 	// Implement the required logic or leave as a stub for testing
 	return nil
 }
 
+// logEventHandler выводит полученные консьюмером события в лог.
+type logEventHandler struct{}
+
+func (h *logEventHandler) Handle(ctx context.Context, event *domain.Event) error {
+	log.Printf("📨 Consumed event %s (%s): %v", event.ID, event.Type, event.Payload)
+	return nil
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
 func main() {
+	os.Exit(run())
+}
+
+// run запускает систему и блокируется до SIGINT/SIGTERM или падения HTTP-сервера,
+// после чего выполняет упорядоченную остановку. Возвращает код выхода процесса.
+func run() int {
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// ctx останавливает фоновые циклы: по сигналу или при падении HTTP-сервера
+	ctx, cancel := context.WithCancel(signalCtx)
+	defer cancel()
+
+	shutdownTimeout, err := time.ParseDuration(getEnv("EVENT_SHUTDOWN_TIMEOUT", "30s"))
+	if err != nil {
+		log.Printf("invalid EVENT_SHUTDOWN_TIMEOUT: %v", err)
+		return exitStartupFailed
+	}
 
 	registry, err := infrastructure.NewEventRegistryFromFile("config/channels.json")
 	if err != nil {
-		log.Fatalf("failed to load event registry: %v", err)
+		log.Printf("failed to load event registry: %v", err)
+		return exitStartupFailed
 	}
 
-	validator, err := domain.NewJSONSchemaValidator("config/schema", registry)
+	const schemaDir = "config/schema"
+	validator, err := domain.NewJSONSchemaValidator(schemaDir, registry)
 	if err != nil {
-		log.Fatalf("failed to init validator: %v", err)
+		log.Printf("failed to init validator: %v", err)
+		return exitStartupFailed
 	}
 
 	// Проверяем что конфиг загрузился
 	topic, schema, err := registry.ResolveChannel("OrderStatusEvent")
 	if err != nil {
-		log.Fatalf("failed to resolve channel: %v", err)
+		log.Printf("failed to resolve channel: %v", err)
+		return exitStartupFailed
 	}
 	log.Printf("Loaded channel: OrderStatusEvent -> topic: %s, schema: %s", topic, schema)
 
+	// Ресурсы, которые закрываются последним этапом остановки
+	var closers []shutdownStep
+
+	// Publishers: backend выбирается по полю type канала
+	brokers := []string{"localhost:9092"} // Kafka brokers
+
+	publisher := infrastructure.NewKafkaPublisher(brokers, registry)
+	publisher.Payloads = validator
+	router := infrastructure.NewRoutingPublisher(registry)
+	register := func(channelType string, p infrastructure.DestinationPublisher) error {
+		if err := router.Register(channelType, p); err != nil {
+			return fmt.Errorf("failed to register publisher: %w", err)
+		}
+		return nil
+	}
+	if err := errors.Join(
+		register("kafka", publisher),
+		register("memory", infrastructure.NewMemoryPublisher(registry)),
+		register("webhook", infrastructure.NewWebhookPublisher(registry)),
+		register("file", infrastructure.NewFilePublisher(registry, getEnv("EVENT_FILE_DIR", "data/events"))),
+	); err != nil {
+		log.Print(err)
+		return exitStartupFailed
+	}
+	if dsn := os.Getenv("EVENT_SQLITE_DSN"); dsn != "" {
+		store, err := infrastructure.NewSQLitePublisher(dsn, registry)
+		if err != nil {
+			log.Printf("failed to open sqlite event store: %v", err)
+			return exitStartupFailed
+		}
+		if err := register("sqlite", store); err != nil {
+			log.Print(err)
+			return exitStartupFailed
+		}
+		closers = append(closers, shutdownStep{"close sqlite event store", func(context.Context) error { return store.Close() }})
+	}
+
 	////////// Start Admin //////
-	adminHandler := iface.NewAdminHandler(registry)
+	reloader := infrastructure.NewConfigReloader(registry, validator, schemaDir)
+	channelAdmin := infrastructure.NewChannelAdmin(reloader, router.Types(),
+		infrastructure.NewChannelAuditLog("config/channels.audit.log"))
+	adminHandler := iface.NewAdminHandler(registry, reloader, channelAdmin)
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("/admin/reload-channels", adminHandler.ReloadChannels)
 	mux.HandleFunc("/admin/channels", adminHandler.GetChannels)
+	mux.HandleFunc("GET /admin/channels/{name}", adminHandler.GetChannel)
+	mux.HandleFunc("POST /admin/channels/{name}", adminHandler.CreateChannel)
+	mux.HandleFunc("PUT /admin/channels/{name}", adminHandler.UpdateChannel)
+	mux.HandleFunc("DELETE /admin/channels/{name}", adminHandler.DeleteChannel)
+	mux.HandleFunc("GET /admin/audit", adminHandler.GetAuditLog)
+	mux.HandleFunc("POST /admin/reload-schemas", adminHandler.ReloadSchemas)
+	mux.HandleFunc("GET /admin/reload-events", adminHandler.GetReloadEvents)
+	mux.HandleFunc("POST /admin/schemas/{name}/check", adminHandler.CheckSchema)
 
-	go func() {
-		log.Println("Admin API started at :8081")
-		log.Fatal(http.ListenAndServe(":8081", mux))
-	}()
+	// Opt-in: автоматическая перезагрузка при изменении channels.json и каталога схем
+	if os.Getenv("EVENT_CONFIG_WATCH") == "true" {
+		watcher, err := infrastructure.NewConfigWatcher(reloader, infrastructure.ConfigWatcherConfig{})
+		if err != nil {
+			log.Printf("failed to start config watcher: %v", err)
+			return exitStartupFailed
+		}
+		go watcher.Run(ctx)
+	}
 
 	//////////////////////////////
 
-	// Kafka
-	brokers := []string{"localhost:9092"} // Kafka brokers
+	// Dead letters: отклоненные события сохраняются в очередь канала (поле dead_letter)
+	var deadLetters *infrastructure.SQLiteDeadLetterStore
+	if dsn := os.Getenv("EVENT_DEAD_LETTER_DSN"); dsn != "" {
+		if deadLetters, err = infrastructure.NewSQLiteDeadLetterStore(dsn, registry); err != nil {
+			log.Printf("failed to open dead letter store: %v", err)
+			return exitStartupFailed
+		}
+		closers = append(closers, shutdownStep{"close dead letter store", func(context.Context) error { return deadLetters.Close() }})
+	}
 
-	publisher := infrastructure.NewKafkaPublisher(brokers, registry)
-	service := application.NewEventService(validator, publisher)
+	// Outbox mode: события сначала фиксируются в SQLite, relay доставляет их в backends в фоне
+	var servicePublisher domain.EventPublisher = router
+	var relay *infrastructure.OutboxRelay
+	relayDone := make(chan struct{})
+	if dsn := os.Getenv("EVENT_OUTBOX_DSN"); dsn != "" {
+		store, err := infrastructure.NewSQLitePublisher(dsn, registry)
+		if err != nil {
+			log.Printf("failed to open outbox store: %v", err)
+			return exitStartupFailed
+		}
+		outbox, err := infrastructure.NewSQLiteOutbox(store)
+		if err != nil {
+			log.Printf("failed to init outbox: %v", err)
+			return exitStartupFailed
+		}
+		relay = infrastructure.NewOutboxRelay(outbox, router, infrastructure.OutboxRelayConfig{})
+		if deadLetters != nil {
+			relay.DeadLetters = deadLetters
+		}
+		go func() {
+			defer close(relayDone)
+			relay.Run(ctx)
+		}()
+		servicePublisher = outbox
+		closers = append(closers, shutdownStep{"close outbox store", func(context.Context) error { return store.Close() }})
+		log.Printf("Outbox mode enabled: %s", dsn)
+	} else {
+		close(relayDone)
+	}
+
+	service := application.NewEventService(validator, servicePublisher)
+
+	if deadLetters != nil {
+		service.DeadLetters = deadLetters
+
+		deadLetterHandler := iface.NewDeadLetterHandler(deadLetters, service)
+		mux.HandleFunc("GET /admin/dead-letters", deadLetterHandler.ListDeadLetters)
+		mux.HandleFunc("GET /admin/dead-letters/{id}", deadLetterHandler.GetDeadLetter)
+		mux.HandleFunc("POST /admin/dead-letters/{id}/replay", deadLetterHandler.ReplayDeadLetter)
+	}
+
+	// Дедупликация: повторы принятых событий (по ID или Idempotency-Key) не публикуются заново
+	if window, dsn := os.Getenv("EVENT_DEDUP_WINDOW"), os.Getenv("EVENT_DEDUP_DSN"); window != "" || dsn != "" {
+		var cfg infrastructure.IdempotencyConfig
+		if window != "" {
+			if cfg.Window, err = time.ParseDuration(window); err != nil {
+				log.Printf("invalid EVENT_DEDUP_WINDOW: %v", err)
+				return exitStartupFailed
+			}
+		}
+		if dsn != "" {
+			store, err := infrastructure.NewSQLiteIdempotencyStore(dsn, cfg)
+			if err != nil {
+				log.Printf("failed to open idempotency store: %v", err)
+				return exitStartupFailed
+			}
+			service.Idempotency = store
+			closers = append(closers, shutdownStep{"close idempotency store", func(context.Context) error { return store.Close() }})
+		} else {
+			service.Idempotency = infrastructure.NewMemoryIdempotencyStore(cfg)
+		}
+		log.Printf("Deduplication enabled (window %s)", getEnv("EVENT_DEDUP_WINDOW", "24h"))
+	}
 
 	// Topics for channels (development)
 	allChannels := registry.GetAllChannels()
 	var topics []string
 	for _, channelInfo := range allChannels {
-		if channelInfo.Type == "kafka" {
-			topics = append(topics, channelInfo.Endpoint)
+		for _, destination := range channelInfo.Targets() {
+			if destination.Type == "kafka" {
+				topics = append(topics, destination.Endpoint)
+			}
 		}
 	}
 
@@ -68,13 +234,112 @@ func main() {
 		log.Printf("Warning: failed to create topics: %v", err)
 	}
 
-	http.HandleFunc("/healthz", iface.HealthCheckHandler)
-	// http.HandleFunc("/readyz", iface.ReadyCheckHandler("localhost:9092"))
+	// Consumer: подписывает обработчики на все kafka-каналы из registry
+	var consumer *infrastructure.KafkaConsumer
+	if groupID := os.Getenv("EVENT_CONSUMER_GROUP"); groupID != "" {
+		dispatcher := application.NewEventDispatcher(validator)
+		for eventType, channelInfo := range allChannels {
+			if channelInfo.Type == "kafka" {
+				dispatcher.Register(eventType, &logEventHandler{})
+			}
+		}
+		consumer = infrastructure.NewKafkaConsumer(infrastructure.KafkaConsumerConfig{
+			Brokers:  brokers,
+			GroupID:  groupID,
+			Payloads: validator,
+		}, registry, dispatcher)
+		if err := consumer.Start(ctx); err != nil {
+			log.Printf("failed to start consumer: %v", err)
+			return exitStartupFailed
+		}
+	}
+
+	apiMux := http.NewServeMux()
+	apiMux.HandleFunc("/healthz", iface.HealthCheckHandler)
+	// apiMux.HandleFunc("/readyz", iface.ReadyCheckHandler("localhost:9092"))
 
 	// Event handler
 	eventHandler := iface.NewEventHandler(service)
-	http.HandleFunc("/event", eventHandler.HandleEvent)
+	if v := os.Getenv("EVENT_REQUEST_TIMEOUT"); v != "" {
+		if eventHandler.Timeout, err = time.ParseDuration(v); err != nil {
+			log.Printf("invalid EVENT_REQUEST_TIMEOUT: %v", err)
+			return exitStartupFailed
+		}
+	}
+	apiMux.HandleFunc("/event", eventHandler.HandleEvent)
+
+	batchHandler := iface.NewBatchEventHandler(service)
+	batchHandler.Timeout = eventHandler.Timeout
+	apiMux.HandleFunc("/events/batch", batchHandler.HandleBatch)
+
+	adminServer := &http.Server{Addr: ":8081", Handler: mux}
+	apiServer := &http.Server{Addr: ":8080", Handler: apiMux}
 
-	log.Println("Event system started at :8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	serverErrors := make(chan error, 2)
+	serve := func(name string, server *http.Server) {
+		log.Printf("%s started at %s", name, server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErrors <- fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	go serve("Admin API", adminServer)
+	go serve("Event system", apiServer)
+
+	code := exitOK
+	select {
+	case <-ctx.Done():
+		log.Printf("🛑 Shutdown signal received, draining (timeout %s)", shutdownTimeout)
+	case err := <-serverErrors:
+		log.Printf("server failed: %v", err)
+		code = exitStartupFailed
+	}
+	// Повторный сигнал во время остановки завершает процесс сразу
+	stop()
+	cancel()
+
+	steps := []shutdownStep{
+		// Перестаем принимать запросы и дожидаемся начатых
+		{"drain event API", apiServer.Shutdown},
+		{"drain admin API", adminServer.Shutdown},
+		// Фоновые циклы останавливаются по отмене ctx
+		{"stop consumer", func(context.Context) error {
+			if consumer != nil {
+				consumer.Wait()
+			}
+			return nil
+		}},
+		{"stop outbox relay", func(context.Context) error {
+			<-relayDone
+			return nil
+		}},
+		// Outbox переживает рестарт, поэтому оставшиеся события не считаются потерянными
+		{"flush outbox", func(ctx context.Context) error {
+			if relay == nil {
+				return nil
+			}
+			remaining, err := relay.Flush(ctx)
+			if err != nil {
+				return err
+			}
+			if remaining > 0 {
+				log.Printf("⚠️ %d events left in outbox, they will be delivered after restart", remaining)
+			}
+			return nil
+		}},
+		// Close сбрасывает буферы async-writers
+		{"close kafka writers", func(context.Context) error {
+			if err := publisher.Close(); err != nil {
+				return err
+			}
+			if n := publisher.AsyncFailures(); n > 0 {
+				return fmt.Errorf("%d async kafka messages were not delivered", n)
+			}
+			return nil
+		}},
+	}
+	if shutdownCode := shutdown(shutdownTimeout, append(steps, closers...)); shutdownCode != exitOK && code == exitOK {
+		code = shutdownCode
+	}
+	log.Printf("Event system stopped (exit code %d)", code)
+	return code
 }
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Коды выхода процесса.
+const (
+	exitOK            = 0
+	exitStartupFailed = 1 // не удалось запуститься или сервер упал
+	exitDropped       = 2 // при остановке часть событий могла быть потеряна
+)
+
+// shutdownStep — один этап остановки. Ошибка этапа означает, что часть событий
+// могла быть потеряна (не дождались запросов, не сбросили буферы и т.п.).
+type shutdownStep struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// shutdown выполняет этапы по порядку с общим дедлайном и возвращает код выхода.
+// Этапы выполняются все, даже если предыдущие завершились ошибкой.
+func shutdown(timeout time.Duration, steps []shutdownStep) int {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	code := exitOK
+	for _, step := range steps {
+		if err := runShutdownStep(ctx, step); err != nil {
+			log.Printf("❌ Shutdown: %s: %v", step.name, err)
+			code = exitDropped
+			continue
+		}
+		log.Printf("Shutdown: %s done", step.name)
+	}
+	return code
+}
+
+// runShutdownStep не дает зависшему этапу задержать остановку дольше дедлайна.
+func runShutdownStep(ctx context.Context, step shutdownStep) error {
+	done := make(chan error, 1)
+	go func() { done <- step.run(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("did not finish before the shutdown deadline: %w", ctx.Err())
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestShutdown_RunsStepsInOrder(t *testing.T) {
+	var order []string
+	step := func(name string) shutdownStep {
+		return shutdownStep{name, func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}}
+	}
+
+	code := shutdown(time.Second, []shutdownStep{step("http"), step("outbox"), step("kafka")})
+
+	if code != exitOK {
+		t.Errorf("expected exit code %d, got %d", exitOK, code)
+	}
+	if len(order) != 3 || order[0] != "http" || order[2] != "kafka" {
+		t.Errorf("expected steps in order, got %v", order)
+	}
+}
+
+func TestShutdown_ReportsDroppedButRunsRemainingSteps(t *testing.T) {
+	closed := false
+	code := shutdown(time.Second, []shutdownStep{
+		{"kafka", func(context.Context) error { return errors.New("3 async kafka messages were not delivered") }},
+		{"close store", func(context.Context) error { closed = true; return nil }},
+	})
+
+	if code != exitDropped {
+		t.Errorf("expected exit code %d, got %d", exitDropped, code)
+	}
+	if !closed {
+		t.Error("expected steps after a failure to run")
+	}
+}
+
+func TestShutdown_StepExceedingDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	start := time.Now()
+	code := shutdown(50*time.Millisecond, []shutdownStep{
+		{"stuck", func(context.Context) error { <-release; return nil }},
+	})
+
+	if code != exitDropped {
+		t.Errorf("expected exit code %d, got %d", exitDropped, code)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("shutdown waited %s for a stuck step", elapsed)
+	}
+}
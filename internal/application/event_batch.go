@@ -0,0 +1,178 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"time"
+)
+
+// BatchMode определяет, что делать с пакетом, в котором есть невалидные события.
+type BatchMode string
+
+const (
+	// BatchModeAtomic — все или ничего: одно невалидное событие отклоняет весь пакет,
+	// а публикация идет одной транзакцией. Требует domain.TransactionalPublisher (outbox).
+	BatchModeAtomic BatchMode = "atomic"
+	// BatchModePartial — валидные события публикуются, невалидные отклоняются по отдельности.
+	BatchModePartial BatchMode = "partial"
+)
+
+// ErrAtomicBatchUnsupported — publisher не умеет публиковать пакет одной транзакцией,
+// поэтому atomic-пакет мог бы опубликоваться частично.
+var ErrAtomicBatchUnsupported = errors.New("atomic batches require a transactional publisher (outbox mode)")
+
+// ParseBatchMode разбирает режим пакета; пустая строка — partial.
+func ParseBatchMode(s string) (BatchMode, error) {
+	switch BatchMode(s) {
+	case "", BatchModePartial:
+		return BatchModePartial, nil
+	case BatchModeAtomic:
+		return BatchModeAtomic, nil
+	}
+	return "", fmt.Errorf("unknown batch mode %q (want atomic or partial)", s)
+}
+
+// BatchItemStatus — итог обработки одного события пакета.
+type BatchItemStatus string
+
+const (
+	BatchItemAccepted BatchItemStatus = "accepted" // опубликовано
+	BatchItemRejected BatchItemStatus = "rejected" // не прошло разбор, валидацию или проверку повторов
+	BatchItemFailed   BatchItemStatus = "failed"   // валидно, но публикация не удалась
+	BatchItemSkipped  BatchItemStatus = "skipped"  // валидно, но пакет отклонен целиком (atomic)
+)
+
+// BatchItemResult — результат по одному событию; Index — позиция события в пакете.
+type BatchItemResult struct {
+	Index      int                `json:"index"`
+	EventID    string             `json:"event_id,omitempty"`
+	Status     BatchItemStatus    `json:"status"`
+	Duplicate  bool               `json:"duplicate,omitempty"` // событие уже было принято ранее и не публиковалось повторно
+	Error      string             `json:"error,omitempty"`
+	Code       string             `json:"code,omitempty"`       // стабильный код ошибки; заполняет транспортный слой
+	Violations []domain.Violation `json:"violations,omitempty"` // нарушения по полям, если событие не прошло валидацию
+	Err        error              `json:"-"`
+}
+
+func (r *BatchItemResult) fail(status BatchItemStatus, err error) {
+	r.Status = status
+	r.Err = err
+	if err != nil {
+		r.Error = err.Error()
+	}
+	var validationErr *domain.EventValidationError
+	if errors.As(err, &validationErr) {
+		r.Violations = validationErr.Violations
+	}
+}
+
+// ProcessBatch проверяет каждое событие пакета независимо и публикует валидные.
+// Если publisher реализует domain.BatchPublisher, события уходят одним вызовом
+// (Kafka пишет их пачками по топикам). Повторы уже принятых событий считаются принятыми
+// и не публикуются. Возвращает результат по каждому событию в порядке пакета.
+// Atomic-пакет без транзакционного publisher'а отклоняется целиком с ErrAtomicBatchUnsupported.
+func (s *EventService) ProcessBatch(ctx context.Context, events []*domain.Event, mode BatchMode) []BatchItemResult {
+	results := make([]BatchItemResult, len(events))
+	if mode == BatchModeAtomic && !s.SupportsAtomicBatch() {
+		for i, event := range events {
+			results[i] = BatchItemResult{Index: i, EventID: event.ID}
+			results[i].fail(BatchItemRejected, ErrAtomicBatchUnsupported)
+		}
+		return results
+	}
+	keys := make([]string, len(events))
+	var fresh []int
+	settled := 0
+	now := time.Now()
+	for i, event := range events {
+		event.ApplyDefaults(now)
+		results[i] = BatchItemResult{Index: i, EventID: event.ID}
+		key, receipt, err := s.reserve(ctx, event, "")
+		if err != nil {
+			status := BatchItemFailed
+			if errors.Is(err, domain.ErrDuplicateInProgress) || errors.Is(err, domain.ErrIdempotencyKeyReused) {
+				status = BatchItemRejected
+			}
+			results[i].fail(status, err)
+			continue
+		}
+		if receipt != nil {
+			results[i].Status = BatchItemAccepted
+			results[i].Duplicate = true
+			settled++
+			continue
+		}
+		if err := s.Validator.Validate(ctx, event); err != nil {
+			s.release(ctx, key)
+			s.deadLetter(ctx, event, domain.DeadLetterStageValidation, err)
+			results[i].fail(BatchItemRejected, err)
+			continue
+		}
+		keys[i] = key
+		fresh = append(fresh, i)
+	}
+
+	if bad := len(events) - len(fresh) - settled; mode == BatchModeAtomic && bad > 0 {
+		skipErr := fmt.Errorf("batch rejected: %d of %d events are invalid", bad, len(events))
+		for i := range results {
+			if results[i].Status == BatchItemAccepted {
+				results[i].Duplicate = false
+				results[i].fail(BatchItemSkipped, skipErr)
+			}
+		}
+		for _, i := range fresh {
+			s.release(ctx, keys[i])
+			results[i].fail(BatchItemSkipped, skipErr)
+		}
+		return results
+	}
+	if len(fresh) == 0 {
+		return results
+	}
+
+	batch := make([]*domain.Event, len(fresh))
+	for j, i := range fresh {
+		batch[j] = events[i]
+	}
+	for j, err := range s.publishBatch(ctx, batch, mode) {
+		i := fresh[j]
+		if err != nil {
+			s.release(ctx, keys[i])
+			s.deadLetter(ctx, events[i], domain.DeadLetterStagePublish, err)
+			results[i].fail(BatchItemFailed, domain.NewPublishError(events[i].ID, err))
+			continue
+		}
+		s.complete(ctx, keys[i], events[i])
+		results[i].Status = BatchItemAccepted
+	}
+	return results
+}
+
+// SupportsAtomicBatch сообщает, может ли publisher сохранить пакет атомарно.
+func (s *EventService) SupportsAtomicBatch() bool {
+	_, ok := s.Publisher.(domain.TransactionalPublisher)
+	return ok
+}
+
+// publishBatch возвращает ошибку публикации по каждому событию.
+func (s *EventService) publishBatch(ctx context.Context, events []*domain.Event, mode BatchMode) []error {
+	if tx, ok := s.Publisher.(domain.TransactionalPublisher); ok && mode == BatchModeAtomic {
+		errs := make([]error, len(events))
+		if err := tx.PublishAll(ctx, events); err != nil {
+			for i := range errs {
+				errs[i] = err
+			}
+		}
+		return errs
+	}
+	if batcher, ok := s.Publisher.(domain.BatchPublisher); ok {
+		return batcher.PublishBatch(ctx, events)
+	}
+	errs := make([]error, len(events))
+	for i, event := range events {
+		errs[i] = s.Publisher.Publish(ctx, event)
+	}
+	return errs
+}
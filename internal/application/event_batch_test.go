@@ -0,0 +1,147 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"event-system/internal/domain"
+	"testing"
+)
+
+func TestEventService_ProcessBatch_PartialPublishesValidEvents(t *testing.T) {
+	mockPublisher, service := setupBatchService(t)
+
+	results := service.ProcessBatch(context.Background(), createMixedBatch(), BatchModePartial)
+
+	assertBatchStatuses(t, results, BatchItemAccepted, BatchItemRejected, BatchItemAccepted)
+	if len(mockPublisher.events) != 2 {
+		t.Fatalf("expected 2 published events, got %d", len(mockPublisher.events))
+	}
+	if results[1].Error == "" || results[1].EventID != "test-event-456" {
+		t.Errorf("expected rejected item to carry event ID and error, got %+v", results[1])
+	}
+}
+
+func TestEventService_ProcessBatch_AtomicRejectsWholeBatch(t *testing.T) {
+	mockPublisher, service := setupBatchService(t)
+
+	results := service.ProcessBatch(context.Background(), createMixedBatch(), BatchModeAtomic)
+
+	assertBatchStatuses(t, results, BatchItemSkipped, BatchItemRejected, BatchItemSkipped)
+	if len(mockPublisher.events) != 0 {
+		t.Errorf("atomic batch with invalid events must not publish, got %d", len(mockPublisher.events))
+	}
+}
+
+func TestEventService_ProcessBatch_AtomicUsesTransactionalPublisher(t *testing.T) {
+	mockPublisher, service := setupBatchService(t)
+	mockPublisher.txErr = errors.New("disk full")
+
+	events := []*domain.Event{createValidOrderStatusEvent(), createValidOrderStatusEvent()}
+	results := service.ProcessBatch(context.Background(), events, BatchModeAtomic)
+
+	assertBatchStatuses(t, results, BatchItemFailed, BatchItemFailed)
+	if mockPublisher.transactions != 1 {
+		t.Errorf("expected one transactional publish, got %d", mockPublisher.transactions)
+	}
+}
+
+func TestEventService_ProcessBatch_AtomicRequiresTransactionalPublisher(t *testing.T) {
+	registry := createTestRegistry(t)
+	publisher := &FakePublisher{}
+	service := NewEventService(createTestValidatorWithRegistry(t, registry), publisher)
+
+	events := []*domain.Event{createValidOrderStatusEvent(), createValidOrderStatusEvent()}
+	results := service.ProcessBatch(context.Background(), events, BatchModeAtomic)
+
+	assertBatchStatuses(t, results, BatchItemRejected, BatchItemRejected)
+	if !errors.Is(results[0].Err, ErrAtomicBatchUnsupported) {
+		t.Errorf("expected ErrAtomicBatchUnsupported, got %v", results[0].Err)
+	}
+	if publisher.called {
+		t.Error("atomic batch without transactions must not publish")
+	}
+}
+
+func TestEventService_ProcessBatch_PartialReportsPublishFailures(t *testing.T) {
+	mockPublisher, service := setupBatchService(t)
+	mockPublisher.failIDs = map[string]bool{"test-event-789": true}
+
+	third := createValidOrderStatusEvent()
+	third.ID = "test-event-789"
+	events := []*domain.Event{createValidOrderStatusEvent(), third}
+	results := service.ProcessBatch(context.Background(), events, BatchModePartial)
+
+	assertBatchStatuses(t, results, BatchItemAccepted, BatchItemFailed)
+}
+
+func TestParseBatchMode(t *testing.T) {
+	if mode, err := ParseBatchMode(""); err != nil || mode != BatchModePartial {
+		t.Errorf("expected partial by default, got %q, %v", mode, err)
+	}
+	if _, err := ParseBatchMode("some"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+// === Test Helpers ===
+
+func setupBatchService(t *testing.T) (*FakeBatchPublisher, *EventService) {
+	registry := createTestRegistry(t)
+	validator := createTestValidatorWithRegistry(t, registry)
+	publisher := &FakeBatchPublisher{}
+	return publisher, NewEventService(validator, publisher)
+}
+
+func createMixedBatch() []*domain.Event {
+	second := createValidOrderStatusEvent()
+	second.ID = "test-event-789"
+	return []*domain.Event{createValidOrderStatusEvent(), createInvalidOrderStatusEvent(), second}
+}
+
+func assertBatchStatuses(t *testing.T, results []BatchItemResult, expected ...BatchItemStatus) {
+	t.Helper()
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+	for i, want := range expected {
+		if results[i].Index != i {
+			t.Errorf("result %d: expected index %d, got %d", i, i, results[i].Index)
+		}
+		if results[i].Status != want {
+			t.Errorf("result %d: expected status %s, got %s (%s)", i, want, results[i].Status, results[i].Error)
+		}
+	}
+}
+
+// FakeBatchPublisher implements both batch interfaces and records what was published.
+type FakeBatchPublisher struct {
+	events       []*domain.Event
+	failIDs      map[string]bool
+	txErr        error
+	transactions int
+}
+
+func (f *FakeBatchPublisher) Publish(ctx context.Context, e *domain.Event) error {
+	return f.PublishBatch(ctx, []*domain.Event{e})[0]
+}
+
+func (f *FakeBatchPublisher) PublishBatch(ctx context.Context, events []*domain.Event) []error {
+	errs := make([]error, len(events))
+	for i, e := range events {
+		if f.failIDs[e.ID] {
+			errs[i] = errors.New("broker unavailable")
+			continue
+		}
+		f.events = append(f.events, e)
+	}
+	return errs
+}
+
+func (f *FakeBatchPublisher) PublishAll(ctx context.Context, events []*domain.Event) error {
+	f.transactions++
+	if f.txErr != nil {
+		return f.txErr
+	}
+	f.events = append(f.events, events...)
+	return nil
+}
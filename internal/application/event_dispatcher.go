@@ -0,0 +1,70 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// EventDispatcher доставляет полученные события зарегистрированным обработчикам по типу события.
+type EventDispatcher struct {
+	Validator domain.EventValidator
+
+	mu       sync.RWMutex
+	handlers map[string][]domain.EventHandler
+}
+
+func NewEventDispatcher(validator domain.EventValidator) *EventDispatcher {
+	return &EventDispatcher{
+		Validator: validator,
+		handlers:  make(map[string][]domain.EventHandler),
+	}
+}
+
+// Register подписывает обработчик на события указанного типа.
+func (d *EventDispatcher) Register(eventType string, handler domain.EventHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[eventType] = append(d.handlers[eventType], handler)
+}
+
+// EventTypes возвращает типы событий, на которые есть подписчики.
+func (d *EventDispatcher) EventTypes() []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	types := make([]string, 0, len(d.handlers))
+	for t := range d.handlers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Dispatch повторно валидирует событие и вызывает все обработчики его типа.
+// Окно timestamp канала не проверяется: оно ограничивает прием, а не задержку потребления.
+// Ошибка валидации возвращается как есть, ошибки обработчиков объединяются.
+// Повторный Dispatch после ошибки снова вызывает все обработчики.
+func (d *EventDispatcher) Dispatch(ctx context.Context, event *domain.Event) error {
+	d.mu.RLock()
+	handlers := d.handlers[event.Type]
+	d.mu.RUnlock()
+
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	if err := d.Validator.Validate(domain.WithoutTimestampWindow(ctx), event); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h.Handle(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("handler %T failed for event %s: %w", h, event.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
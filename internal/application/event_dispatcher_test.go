@@ -0,0 +1,147 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"event-system/internal/domain"
+	"event-system/internal/infrastructure"
+	"testing"
+	"time"
+)
+
+func TestEventDispatcher_Dispatch_CallsHandlersForType(t *testing.T) {
+	dispatcher := setupEventDispatcher(t)
+	orders := &FakeHandler{}
+	other := &FakeHandler{}
+	dispatcher.Register("OrderStatusEvent", orders)
+	dispatcher.Register("OtherEvent", other)
+
+	err := dispatcher.Dispatch(context.Background(), createValidOrderStatusEvent())
+
+	assertNoError(t, err)
+	if len(orders.events) != 1 {
+		t.Errorf("expected order handler to be called once, got %d", len(orders.events))
+	}
+	if len(other.events) != 0 {
+		t.Errorf("expected other handler not to be called, got %d", len(other.events))
+	}
+}
+
+func TestEventDispatcher_Dispatch_RevalidatesEvent(t *testing.T) {
+	dispatcher := setupEventDispatcher(t)
+	handler := &FakeHandler{}
+	dispatcher.Register("OrderStatusEvent", handler)
+
+	err := dispatcher.Dispatch(context.Background(), createInvalidOrderStatusEvent())
+
+	var validationErr *domain.EventValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if len(handler.events) != 0 {
+		t.Error("handler should NOT be called for an invalid event")
+	}
+}
+
+func TestEventDispatcher_Dispatch_ReturnsHandlerErrors(t *testing.T) {
+	dispatcher := setupEventDispatcher(t)
+	failing := &FakeHandler{err: errors.New("boom")}
+	healthy := &FakeHandler{}
+	dispatcher.Register("OrderStatusEvent", failing)
+	dispatcher.Register("OrderStatusEvent", healthy)
+
+	err := dispatcher.Dispatch(context.Background(), createValidOrderStatusEvent())
+
+	if err == nil {
+		t.Fatal("expected handler error, got nil")
+	}
+	if len(healthy.events) != 1 {
+		t.Error("expected remaining handlers to run after a failure")
+	}
+}
+
+func TestEventDispatcher_Dispatch_RetryRedeliversToAllHandlers(t *testing.T) {
+	dispatcher := setupEventDispatcher(t)
+	flaky := &FakeHandler{err: errors.New("boom")}
+	idempotent := &IdempotentHandler{}
+	dispatcher.Register("OrderStatusEvent", flaky)
+	dispatcher.Register("OrderStatusEvent", idempotent)
+	event := createValidOrderStatusEvent()
+
+	if err := dispatcher.Dispatch(context.Background(), event); err == nil {
+		t.Fatal("expected handler error on the first attempt")
+	}
+	// The consumer retries the whole dispatch, as it does for a handler error
+	flaky.err = nil
+	assertNoError(t, dispatcher.Dispatch(context.Background(), event))
+
+	if idempotent.calls != 2 {
+		t.Errorf("expected the healthy handler to be called again on retry, got %d calls", idempotent.calls)
+	}
+	if len(idempotent.applied) != 1 {
+		t.Errorf("expected an idempotent handler to apply the event once, got %d", len(idempotent.applied))
+	}
+	if len(flaky.events) != 1 {
+		t.Errorf("expected the failing handler to succeed on retry, got %d", len(flaky.events))
+	}
+}
+
+func TestEventDispatcher_Dispatch_IgnoresTimestampWindow(t *testing.T) {
+	registry := createTestRegistry(t)
+	channels := registry.GetAllChannels()
+	info := channels["OrderStatusEvent"]
+	info.TimestampWindow = &infrastructure.TimestampWindow{MaxPastMs: 60000}
+	channels["OrderStatusEvent"] = info
+	registry.Apply(channels)
+	dispatcher := NewEventDispatcher(createTestValidatorWithRegistry(t, registry))
+	handler := &FakeHandler{}
+	dispatcher.Register("OrderStatusEvent", handler)
+
+	// Consumer lag beyond max_past must not drop a valid event
+	event := createValidOrderStatusEvent()
+	event.Timestamp = time.Now().Add(-time.Hour)
+	err := dispatcher.Dispatch(context.Background(), event)
+
+	assertNoError(t, err)
+	if len(handler.events) != 1 {
+		t.Errorf("expected handler to be called once, got %d", len(handler.events))
+	}
+}
+
+// === Setup Helpers ===
+
+func setupEventDispatcher(t *testing.T) *EventDispatcher {
+	registry := createTestRegistry(t)
+	validator := createTestValidatorWithRegistry(t, registry)
+	return NewEventDispatcher(validator)
+}
+
+// === Mock Handler ===
+
+type FakeHandler struct {
+	err    error
+	events []*domain.Event
+}
+
+func (f *FakeHandler) Handle(ctx context.Context, e *domain.Event) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.events = append(f.events, e)
+	return nil
+}
+
+// IdempotentHandler applies each event ID once, however many times it is delivered.
+type IdempotentHandler struct {
+	calls   int
+	applied map[string]bool
+}
+
+func (h *IdempotentHandler) Handle(ctx context.Context, e *domain.Event) error {
+	h.calls++
+	if h.applied == nil {
+		h.applied = make(map[string]bool)
+	}
+	h.applied[e.ID] = true
+	return nil
+}
@@ -1,12 +1,17 @@
 package application
 
 import (
+	"context"
+	"errors"
 	"event-system/internal/domain"
+	"log"
 )
 
 type EventService struct {
-	Validator domain.EventValidator
-	Publisher domain.EventPublisher
+	Validator   domain.EventValidator
+	Publisher   domain.EventPublisher
+	DeadLetters domain.DeadLetterQueue  // optional: куда складывать отклоненные события
+	Idempotency domain.IdempotencyStore // optional: дедупликация по ID события или Idempotency-Key
 }
 
 func NewEventService(validator domain.EventValidator, publisher domain.EventPublisher) *EventService {
@@ -16,10 +21,35 @@ func NewEventService(validator domain.EventValidator, publisher domain.EventPubl
 	}
 }
 
-// Validate and publish event
-func (s *EventService) ProcessEvent(event *domain.Event) error {
-	if err := s.Validator.Validate(event); err != nil {
+// Validate and publish event. Отмена ctx (клиент отключился, сервер останавливается)
+// прерывает проверку и публикацию. Повтор уже принятого события не публикуется.
+func (s *EventService) ProcessEvent(ctx context.Context, event *domain.Event) error {
+	_, err := s.Submit(ctx, event, "")
+	return err
+}
+
+func (s *EventService) process(ctx context.Context, event *domain.Event) error {
+	if err := s.Validator.Validate(ctx, event); err != nil {
+		s.deadLetter(ctx, event, domain.DeadLetterStageValidation, err)
 		return err
 	}
-	return s.Publisher.Publish(event)
+	if err := s.Publisher.Publish(ctx, event); err != nil {
+		s.deadLetter(ctx, event, domain.DeadLetterStagePublish, err)
+		return domain.NewPublishError(event.ID, err)
+	}
+	return nil
+}
+
+func (s *EventService) deadLetter(ctx context.Context, event *domain.Event, stage domain.DeadLetterStage, cause error) {
+	if s.DeadLetters == nil {
+		return
+	}
+	// Отмена запроса — не отказ события: клиент не получил ответа и повторит отправку
+	if errors.Is(cause, context.Canceled) || errors.Is(cause, context.DeadlineExceeded) {
+		return
+	}
+	// Запись в очередь не должна обрываться, если ctx отменят во время сохранения
+	if err := s.DeadLetters.Add(context.WithoutCancel(ctx), event, stage, cause); err != nil {
+		log.Printf("failed to dead-letter event %s: %v", event.ID, err)
+	}
 }
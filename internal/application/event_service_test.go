@@ -1,7 +1,9 @@
 package application
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"event-system/internal/domain"
 	"event-system/internal/infrastructure"
 	"os"
@@ -14,7 +16,7 @@ func TestEventService_ProcessEvent_Success(t *testing.T) {
 	mockPublisher, service := setupEventService(t)
 
 	event := createValidOrderStatusEvent()
-	err := service.ProcessEvent(event)
+	err := service.ProcessEvent(context.Background(), event)
 
 	assertNoError(t, err)
 	assertPublisherCalled(t, mockPublisher, event)
@@ -24,7 +26,7 @@ func TestEventService_ProcessEvent_ValidationError(t *testing.T) {
 	mockPublisher, service := setupEventService(t)
 
 	event := createInvalidOrderStatusEvent()
-	err := service.ProcessEvent(event)
+	err := service.ProcessEvent(context.Background(), event)
 
 	assertValidationError(t, err)
 	assertPublisherNotCalled(t, mockPublisher)
@@ -34,12 +36,91 @@ func TestEventService_ProcessEvent_UnknownEventType(t *testing.T) {
 	mockPublisher, service := setupEventService(t)
 
 	event := createUnknownTypeEvent()
-	err := service.ProcessEvent(event)
+	err := service.ProcessEvent(context.Background(), event)
 
 	assertSchemaNotFoundError(t, err)
 	assertPublisherNotCalled(t, mockPublisher)
 }
 
+func TestEventService_ProcessEvent_DeadLettersValidationError(t *testing.T) {
+	_, service := setupEventService(t)
+	deadLetters := &FakeDeadLetterQueue{}
+	service.DeadLetters = deadLetters
+
+	event := createInvalidOrderStatusEvent()
+	service.ProcessEvent(context.Background(), event)
+
+	assertDeadLettered(t, deadLetters, event, domain.DeadLetterStageValidation)
+}
+
+func TestEventService_ProcessEvent_DeadLettersPublishError(t *testing.T) {
+	mockPublisher, service := setupEventService(t)
+	mockPublisher.err = errors.New("broker unavailable")
+	deadLetters := &FakeDeadLetterQueue{}
+	service.DeadLetters = deadLetters
+
+	event := createValidOrderStatusEvent()
+	err := service.ProcessEvent(context.Background(), event)
+
+	if err == nil {
+		t.Fatal("expected publish error, got nil")
+	}
+	assertDeadLettered(t, deadLetters, event, domain.DeadLetterStagePublish)
+}
+
+func TestEventService_ProcessEvent_RejectsMissingPartitionKeyBeforePublishing(t *testing.T) {
+	registry := createTestRegistry(t)
+	channels := registry.GetAllChannels()
+	info := channels["OrderStatusEvent"]
+	info.PartitionKey = "/customer_id"
+	channels["OrderStatusEvent"] = info
+	registry.Apply(channels)
+	mockPublisher := &FakePublisher{}
+	service := NewEventService(createTestValidatorWithRegistry(t, registry), mockPublisher)
+	deadLetters := &FakeDeadLetterQueue{}
+	service.DeadLetters = deadLetters
+
+	// With an outbox publisher the event would be accepted and fail only in the relay
+	event := createValidOrderStatusEvent()
+	err := service.ProcessEvent(context.Background(), event)
+
+	if !errors.Is(err, domain.ErrMissingPartitionKey) {
+		t.Fatalf("expected ErrMissingPartitionKey, got %v", err)
+	}
+	assertPublisherNotCalled(t, mockPublisher)
+	assertDeadLettered(t, deadLetters, event, domain.DeadLetterStageValidation)
+}
+
+func TestEventService_ProcessEvent_CanceledContext(t *testing.T) {
+	mockPublisher, service := setupEventService(t)
+	deadLetters := &FakeDeadLetterQueue{}
+	service.DeadLetters = deadLetters
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := service.ProcessEvent(ctx, createValidOrderStatusEvent())
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	assertPublisherNotCalled(t, mockPublisher)
+	if len(deadLetters.letters) != 0 {
+		t.Errorf("canceled requests must not be dead-lettered, got %d", len(deadLetters.letters))
+	}
+}
+
+func TestEventService_ProcessEvent_PassesContextToPublisher(t *testing.T) {
+	mockPublisher, service := setupEventService(t)
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+	assertNoError(t, service.ProcessEvent(ctx, createValidOrderStatusEvent()))
+
+	if mockPublisher.ctx == nil || mockPublisher.ctx.Value(ctxKey{}) != "request-1" {
+		t.Error("expected the request context to reach the publisher")
+	}
+}
+
 // === Test Helpers ===
 
 func setupEventService(t *testing.T) (*FakePublisher, *EventService) {
@@ -132,6 +213,18 @@ func assertPublisherNotCalled(t *testing.T, publisher *FakePublisher) {
 	}
 }
 
+func assertDeadLettered(t *testing.T, queue *FakeDeadLetterQueue, event *domain.Event, stage domain.DeadLetterStage) {
+	if len(queue.letters) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(queue.letters))
+	}
+	if queue.letters[0].Event.ID != event.ID {
+		t.Errorf("expected dead-lettered event '%s', got '%s'", event.ID, queue.letters[0].Event.ID)
+	}
+	if queue.letters[0].Stage != stage {
+		t.Errorf("expected stage '%s', got '%s'", stage, queue.letters[0].Stage)
+	}
+}
+
 // === Setup Helpers ===
 
 func createTestRegistry(t *testing.T) *infrastructure.EventRegistry {
@@ -201,10 +294,24 @@ func createTestValidatorWithRegistry(t *testing.T, registry *infrastructure.Even
 type FakePublisher struct {
 	called bool
 	event  *domain.Event
+	ctx    context.Context
+	err    error
 }
 
-func (f *FakePublisher) Publish(e *domain.Event) error {
+func (f *FakePublisher) Publish(ctx context.Context, e *domain.Event) error {
 	f.called = true
+	f.ctx = ctx
 	f.event = e
+	return f.err
+}
+
+// === Mock Dead Letter Queue ===
+
+type FakeDeadLetterQueue struct {
+	letters []domain.DeadLetter
+}
+
+func (f *FakeDeadLetterQueue) Add(ctx context.Context, e *domain.Event, stage domain.DeadLetterStage, cause error) error {
+	f.letters = append(f.letters, domain.DeadLetter{Event: e, Stage: stage, Reason: cause.Error()})
 	return nil
 }
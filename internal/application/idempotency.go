@@ -0,0 +1,95 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"event-system/internal/domain"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Receipt — итог приема события. Для повтора возвращается результат первого приема.
+type Receipt struct {
+	EventID    string    `json:"id"`
+	AcceptedAt time.Time `json:"accepted_at"`
+	Duplicate  bool      `json:"duplicate,omitempty"`
+}
+
+// Submit принимает событие: заполняет пустые ID и timestamp, затем проверяет повтор.
+// Ключ дедупликации — idempotencyKey, если он задан, иначе ID события.
+// Повтор уже принятого события не публикуется повторно.
+func (s *EventService) Submit(ctx context.Context, event *domain.Event, idempotencyKey string) (*Receipt, error) {
+	event.ApplyDefaults(time.Now())
+	key, receipt, err := s.reserve(ctx, event, idempotencyKey)
+	if err != nil || receipt != nil {
+		return receipt, err
+	}
+	if err := s.process(ctx, event); err != nil {
+		s.release(ctx, key)
+		return nil, err
+	}
+	return s.complete(ctx, key, event), nil
+}
+
+// reserve занимает ключ события. Возвращает квитанцию, если событие уже принято;
+// пустой ключ — дедупликация выключена или ключа нет.
+func (s *EventService) reserve(ctx context.Context, event *domain.Event, idempotencyKey string) (string, *Receipt, error) {
+	if s.Idempotency == nil {
+		return "", nil, nil
+	}
+	key := "event:" + event.ID
+	if idempotencyKey != "" {
+		key = "key:" + idempotencyKey
+	}
+
+	fingerprint := eventFingerprint(event)
+	existing, reserved, err := s.Idempotency.Reserve(ctx, domain.IdempotencyRecord{Key: key, EventID: event.ID, Fingerprint: fingerprint})
+	if err != nil {
+		return "", nil, fmt.Errorf("idempotency check failed: %w", err)
+	}
+	if reserved {
+		return key, nil, nil
+	}
+	if existing.Fingerprint != fingerprint {
+		return "", nil, domain.ErrIdempotencyKeyReused
+	}
+	if existing.Pending() {
+		return "", nil, domain.ErrDuplicateInProgress
+	}
+	log.Printf("♻️ Duplicate of event %s (accepted at %s), not publishing again", existing.EventID, existing.AcceptedAt.Format(time.RFC3339))
+	return "", &Receipt{EventID: existing.EventID, AcceptedAt: existing.AcceptedAt, Duplicate: true}, nil
+}
+
+// complete и release не должны обрываться отменой запроса: событие уже опубликовано или отклонено.
+func (s *EventService) complete(ctx context.Context, key string, event *domain.Event) *Receipt {
+	receipt := &Receipt{EventID: event.ID, AcceptedAt: time.Now().UTC()}
+	if key != "" {
+		if err := s.Idempotency.Complete(context.WithoutCancel(ctx), key, receipt.AcceptedAt); err != nil {
+			log.Printf("failed to remember accepted event %s: %v", event.ID, err)
+		}
+	}
+	return receipt
+}
+
+func (s *EventService) release(ctx context.Context, key string) {
+	if key == "" {
+		return
+	}
+	if err := s.Idempotency.Release(context.WithoutCancel(ctx), key); err != nil {
+		log.Printf("failed to release idempotency key %s: %v", key, err)
+	}
+}
+
+// eventFingerprint — хеш типа и payload события. json.Marshal сортирует ключи map,
+// поэтому одинаковое содержимое дает одинаковый хеш.
+func eventFingerprint(event *domain.Event) string {
+	data, _ := json.Marshal(struct {
+		Type    string                 `json:"type"`
+		Payload map[string]interface{} `json:"payload"`
+	}{event.Type, event.Payload})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
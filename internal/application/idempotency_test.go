@@ -0,0 +1,89 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"event-system/internal/domain"
+	"event-system/internal/infrastructure"
+	"testing"
+)
+
+func TestEventService_Submit_DuplicateReturnsOriginalReceipt(t *testing.T) {
+	mockPublisher, service := setupBatchService(t)
+	service.Idempotency = infrastructure.NewMemoryIdempotencyStore(infrastructure.IdempotencyConfig{})
+
+	first, err := service.Submit(context.Background(), createValidOrderStatusEvent(), "")
+	assertNoError(t, err)
+	retry, err := service.Submit(context.Background(), createValidOrderStatusEvent(), "")
+	assertNoError(t, err)
+
+	if !retry.Duplicate || retry.EventID != first.EventID || !retry.AcceptedAt.Equal(first.AcceptedAt) {
+		t.Errorf("expected original receipt %+v, got %+v", first, retry)
+	}
+	if len(mockPublisher.events) != 1 {
+		t.Errorf("expected duplicate not to be published, got %d publications", len(mockPublisher.events))
+	}
+}
+
+func TestEventService_Submit_IdempotencyKey(t *testing.T) {
+	mockPublisher, service := setupBatchService(t)
+	service.Idempotency = infrastructure.NewMemoryIdempotencyStore(infrastructure.IdempotencyConfig{})
+
+	first := createValidOrderStatusEvent()
+	_, err := service.Submit(context.Background(), first, "client-key-1")
+	assertNoError(t, err)
+
+	// Same key, new event ID, same content: a retry of the original request
+	retry := createValidOrderStatusEvent()
+	retry.ID = "test-event-retry"
+	receipt, err := service.Submit(context.Background(), retry, "client-key-1")
+	assertNoError(t, err)
+	if !receipt.Duplicate || receipt.EventID != first.ID {
+		t.Errorf("expected receipt for original event %s, got %+v", first.ID, receipt)
+	}
+
+	// Same key, different payload: key reuse
+	changed := createValidOrderStatusEvent()
+	changed.Payload["status"] = "shipped"
+	if _, err := service.Submit(context.Background(), changed, "client-key-1"); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+		t.Errorf("expected ErrIdempotencyKeyReused, got %v", err)
+	}
+	if len(mockPublisher.events) != 1 {
+		t.Errorf("expected one publication, got %d", len(mockPublisher.events))
+	}
+}
+
+func TestEventService_Submit_FailedEventCanBeRetried(t *testing.T) {
+	mockPublisher, service := setupBatchService(t)
+	service.Idempotency = infrastructure.NewMemoryIdempotencyStore(infrastructure.IdempotencyConfig{})
+	event := createValidOrderStatusEvent()
+
+	mockPublisher.failIDs = map[string]bool{event.ID: true}
+	if _, err := service.Submit(context.Background(), event, ""); err == nil {
+		t.Fatal("expected publish error")
+	}
+	mockPublisher.failIDs = nil
+	receipt, err := service.Submit(context.Background(), event, "")
+	assertNoError(t, err)
+	if receipt.Duplicate || len(mockPublisher.events) != 1 {
+		t.Errorf("expected retry to be published, got %+v and %d publications", receipt, len(mockPublisher.events))
+	}
+}
+
+func TestEventService_ProcessBatch_Deduplicates(t *testing.T) {
+	mockPublisher, service := setupBatchService(t)
+	service.Idempotency = infrastructure.NewMemoryIdempotencyStore(infrastructure.IdempotencyConfig{})
+	assertNoError(t, service.ProcessEvent(context.Background(), createValidOrderStatusEvent()))
+
+	second := createValidOrderStatusEvent()
+	second.ID = "test-event-789"
+	results := service.ProcessBatch(context.Background(), []*domain.Event{createValidOrderStatusEvent(), second}, BatchModePartial)
+
+	assertBatchStatuses(t, results, BatchItemAccepted, BatchItemAccepted)
+	if !results[0].Duplicate || results[1].Duplicate {
+		t.Errorf("expected only the first item to be a duplicate, got %+v", results)
+	}
+	if len(mockPublisher.events) != 2 {
+		t.Errorf("expected 2 publications in total, got %d", len(mockPublisher.events))
+	}
+}
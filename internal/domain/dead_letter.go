@@ -0,0 +1,40 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type DeadLetterStage string
+
+const (
+	DeadLetterStageValidation DeadLetterStage = "validation"
+	DeadLetterStagePublish    DeadLetterStage = "publish"
+)
+
+// DeadLetter — событие, которое не удалось провалидировать или опубликовать.
+type DeadLetter struct {
+	ID          string          `json:"id"`
+	Destination string          `json:"destination"`
+	Stage       DeadLetterStage `json:"stage"`
+	Reason      string          `json:"reason"`
+	Attempts    int             `json:"attempts"`
+	Event       *Event          `json:"event"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	ReplayedAt  *time.Time      `json:"replayed_at,omitempty"`
+}
+
+// MarshalJSON отдает событие dead letter в представлении EventJSON.
+func (d DeadLetter) MarshalJSON() ([]byte, error) {
+	type deadLetter DeadLetter
+	return json.Marshal(struct {
+		deadLetter
+		Event *EventJSON `json:"event"`
+	}{deadLetter(d), NewEventJSON(d.Event)})
+}
+
+type DeadLetterQueue interface {
+	Add(ctx context.Context, event *Event, stage DeadLetterStage, cause error) error
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnknownEventType — для типа события не настроен канал.
+	ErrUnknownEventType = errors.New("unknown event type")
+	// ErrSchemaNotFound — канал ссылается на схему или версию схемы, которая не загружена.
+	ErrSchemaNotFound = errors.New("schema not found")
+	// ErrInvalidSchema — файл схемы в каталоге не разбирается или противоречит другим файлам.
+	ErrInvalidSchema = errors.New("invalid schema")
+	// ErrPublishRejected — получатель окончательно отклонил событие; повтор не поможет.
+	ErrPublishRejected = errors.New("event rejected by receiver")
+	// ErrMissingPartitionKey — в payload нет поля partition_key канала, или оно пустое
+	// либо не скаляр. Ошибка клиента: событие не опубликовать, пока его не исправят.
+	ErrMissingPartitionKey = errors.New("partition key not found in payload")
+)
+
+// PublishError — валидное событие не удалось опубликовать. Permanent — получатель
+// отклонил событие и повтор не поможет; иначе сбой временный и повтор допустим.
+type PublishError struct {
+	EventID   string
+	Permanent bool
+	Err       error
+}
+
+// NewPublishError оборачивает ошибку publisher'а; сбой постоянный, если в цепочке есть ErrPublishRejected.
+func NewPublishError(eventID string, err error) *PublishError {
+	return &PublishError{EventID: eventID, Permanent: errors.Is(err, ErrPublishRejected), Err: err}
+}
+
+func (e *PublishError) Error() string {
+	return fmt.Sprintf("failed to publish event %s: %v", e.EventID, e.Err)
+}
+
+func (e *PublishError) Unwrap() error {
+	return e.Err
+}
@@ -1,16 +1,22 @@
 package domain
 
 import (
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Event сериализуется в JSON без тегов: ключи ID, Type, Timestamp, Payload — формат
+// значения сообщения Kafka. Для HTTP API, вебхуков и хранилищ используется EventJSON.
 type Event struct {
-	ID        string
-	Type      string
-	Timestamp time.Time
-	Payload   map[string]interface{}
+	ID            string
+	Type          string
+	Timestamp     time.Time
+	SchemaVersion int `json:",omitempty"` // 0 — версия по умолчанию для канала
+	Payload       map[string]interface{}
+	Metadata      *EventMetadata `json:",omitempty"`
 }
 
 func NewEvent(eventType string, payload map[string]interface{}) *Event {
@@ -21,3 +27,59 @@ func NewEvent(eventType string, payload map[string]interface{}) *Event {
 		Payload:   payload,
 	}
 }
+
+// ApplyDefaults проставляет то, что клиент не передал: UUID вместо пустого ID
+// и время приема (UTC) вместо нулевого timestamp.
+func (e *Event) ApplyDefaults(now time.Time) {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = now.UTC()
+	}
+}
+
+// eventIDRe — допустимый ID: UUID, ULID или строка вида order-123,
+// до 128 символов из букв, цифр и . _ : -
+var eventIDRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]{0,127}$`)
+
+// ValidateEventID проверяет формат ID события.
+func ValidateEventID(id string) error {
+	if !eventIDRe.MatchString(id) {
+		return NewViolationError(Violation{
+			Pointer:  "/id",
+			Rule:     "pattern",
+			Expected: eventIDRe.String(),
+			Message:  fmt.Sprintf("malformed event id %q: want up to 128 letters, digits, '.', '_', ':' or '-'", id),
+		})
+	}
+	return nil
+}
+
+// TimestampWindow — насколько timestamp события может отставать от времени сервера
+// или опережать его. Нулевое поле — без ограничения.
+type TimestampWindow struct {
+	MaxPast   time.Duration
+	MaxFuture time.Duration
+}
+
+// Check возвращает ошибку валидации, если ts выходит за окно относительно now.
+func (w TimestampWindow) Check(ts, now time.Time) error {
+	if w.MaxFuture > 0 && ts.After(now.Add(w.MaxFuture)) {
+		return NewViolationError(Violation{
+			Pointer:  "/timestamp",
+			Rule:     "max_future",
+			Expected: w.MaxFuture.String(),
+			Message:  fmt.Sprintf("timestamp %s is more than %s in the future", ts.Format(time.RFC3339), w.MaxFuture),
+		})
+	}
+	if w.MaxPast > 0 && ts.Before(now.Add(-w.MaxPast)) {
+		return NewViolationError(Violation{
+			Pointer:  "/timestamp",
+			Rule:     "max_past",
+			Expected: w.MaxPast.String(),
+			Message:  fmt.Sprintf("timestamp %s is more than %s in the past", ts.Format(time.RFC3339), w.MaxPast),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// EventJSON — представление события в HTTP API, телах вебхуков, файлах и хранилищах:
+// ключи в snake_case (id, type, timestamp, schema_version, payload, metadata).
+type EventJSON struct {
+	ID            string                 `json:"id"`
+	Type          string                 `json:"type"`
+	Timestamp     time.Time              `json:"timestamp"`
+	SchemaVersion int                    `json:"schema_version,omitempty"`
+	Payload       map[string]interface{} `json:"payload"`
+	Metadata      *EventMetadata         `json:"metadata,omitempty"`
+}
+
+// NewEventJSON возвращает JSON-представление события; для nil — nil.
+func NewEventJSON(e *Event) *EventJSON {
+	if e == nil {
+		return nil
+	}
+	return &EventJSON{
+		ID:            e.ID,
+		Type:          e.Type,
+		Timestamp:     e.Timestamp,
+		SchemaVersion: e.SchemaVersion,
+		Payload:       e.Payload,
+		Metadata:      e.Metadata,
+	}
+}
+
+// Event восстанавливает событие из JSON-представления; для nil — nil.
+func (j *EventJSON) Event() *Event {
+	if j == nil {
+		return nil
+	}
+	return &Event{
+		ID:            j.ID,
+		Type:          j.Type,
+		Timestamp:     j.Timestamp,
+		SchemaVersion: j.SchemaVersion,
+		Payload:       j.Payload,
+		Metadata:      j.Metadata,
+	}
+}
@@ -0,0 +1,157 @@
+package domain
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"sort"
+)
+
+const maxMetadataHeaders = 32
+
+// EventMetadata — сквозные данные события для трассировки: какой запрос или событие
+// его породили, кто и от имени какого tenant'а его отправил.
+type EventMetadata struct {
+	CorrelationID string            `json:"correlation_id,omitempty"` // общий ID цепочки событий
+	CausationID   string            `json:"causation_id,omitempty"`   // ID события или запроса-причины
+	Source        string            `json:"source,omitempty"`         // сервис-отправитель
+	Tenant        string            `json:"tenant,omitempty"`
+	Actor         string            `json:"actor,omitempty"` // пользователь или система, от имени которой отправлено
+	Headers       map[string]string `json:"headers,omitempty"`
+}
+
+// Fields возвращает заполненные стандартные поля метаданных под именами
+// заголовков сообщения (correlation-id, causation-id, source, tenant, actor).
+func (m *EventMetadata) Fields() map[string]string {
+	fields := make(map[string]string)
+	if m == nil {
+		return fields
+	}
+	for name, value := range map[string]string{
+		"correlation-id": m.CorrelationID,
+		"causation-id":   m.CausationID,
+		"source":         m.Source,
+		"tenant":         m.Tenant,
+		"actor":          m.Actor,
+	} {
+		if value != "" {
+			fields[name] = value
+		}
+	}
+	return fields
+}
+
+// SetField заполняет стандартное поле по имени заголовка; false — имя не стандартное.
+func (m *EventMetadata) SetField(name, value string) bool {
+	switch name {
+	case "correlation-id":
+		m.CorrelationID = value
+	case "causation-id":
+		m.CausationID = value
+	case "source":
+		m.Source = value
+	case "tenant":
+		m.Tenant = value
+	case "actor":
+		m.Actor = value
+	default:
+		return false
+	}
+	return true
+}
+
+// Merge возвращает копию m, дополненную значениями из defaults: поля и заголовки,
+// уже заданные в m, не меняются. Возвращает nil, если оба пусты.
+func (m *EventMetadata) Merge(defaults *EventMetadata) *EventMetadata {
+	merged := &EventMetadata{}
+	if m != nil {
+		*merged = *m
+		merged.Headers = maps.Clone(m.Headers)
+	}
+	if defaults != nil {
+		set := merged.Fields()
+		for name, value := range defaults.Fields() {
+			if _, ok := set[name]; !ok {
+				merged.SetField(name, value)
+			}
+		}
+		for name, value := range defaults.Headers {
+			if _, ok := merged.Headers[name]; ok {
+				continue
+			}
+			if merged.Headers == nil {
+				merged.Headers = make(map[string]string)
+			}
+			merged.Headers[name] = value
+		}
+	}
+	if merged.IsEmpty() {
+		return nil
+	}
+	return merged
+}
+
+func (m *EventMetadata) IsEmpty() bool {
+	return m == nil || (len(m.Fields()) == 0 && len(m.Headers) == 0)
+}
+
+// HeaderNames возвращает имена свободных заголовков по алфавиту.
+func (m *EventMetadata) HeaderNames() []string {
+	if m == nil {
+		return nil
+	}
+	names := make([]string, 0, len(m.Headers))
+	for name := range m.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// metadataHeaderRe — допустимое имя свободного заголовка.
+var metadataHeaderRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// reservedHeaders — имена, которые publisher'ы используют сами.
+var reservedHeaders = map[string]bool{
+	"event-type": true, "schema-version": true, "event-id": true, "event-timestamp": true,
+	"content-type": true, "schema-id": true, "proto-message": true,
+	"correlation-id": true, "causation-id": true, "source": true, "tenant": true, "actor": true,
+}
+
+// Validate проверяет свободные заголовки: не больше 32, имена в нижнем регистре
+// из букв, цифр и . _ - и не совпадают со стандартными заголовками сообщения.
+func (m *EventMetadata) Validate() error {
+	if m == nil {
+		return nil
+	}
+	if len(m.Headers) > maxMetadataHeaders {
+		return NewViolationError(Violation{
+			Pointer:  "/metadata/headers",
+			Rule:     "max_items",
+			Expected: maxMetadataHeaders,
+			Message:  fmt.Sprintf("too many metadata headers: %d, limit %d", len(m.Headers), maxMetadataHeaders),
+		})
+	}
+	var violations []Violation
+	for _, name := range m.HeaderNames() {
+		pointer := "/metadata/headers/" + escapePointer(name)
+		if !metadataHeaderRe.MatchString(name) {
+			violations = append(violations, Violation{
+				Pointer:  pointer,
+				Rule:     "pattern",
+				Expected: metadataHeaderRe.String(),
+				Message:  fmt.Sprintf("malformed metadata header name %q: want lowercase letters, digits, '.', '_' or '-'", name),
+			})
+		} else if reservedHeaders[name] {
+			violations = append(violations, Violation{
+				Pointer: pointer,
+				Rule:    "reserved",
+				Message: fmt.Sprintf("metadata header %q is reserved", name),
+			})
+		}
+	}
+	if len(violations) > 0 {
+		return NewViolationError(violations...)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestEventMetadata_MergeKeepsEventValues(t *testing.T) {
+	event := &EventMetadata{CorrelationID: "from-body", Headers: map[string]string{"origin": "body"}}
+	request := &EventMetadata{CorrelationID: "from-header", Tenant: "acme", Headers: map[string]string{"origin": "header", "region": "eu"}}
+
+	merged := event.Merge(request)
+
+	if merged.CorrelationID != "from-body" || merged.Tenant != "acme" {
+		t.Errorf("expected body fields to win and header fields to fill gaps, got %+v", merged)
+	}
+	if merged.Headers["origin"] != "body" || merged.Headers["region"] != "eu" {
+		t.Errorf("unexpected merged headers: %v", merged.Headers)
+	}
+	if len(event.Headers) != 1 {
+		t.Errorf("merge must not modify the original metadata, got %v", event.Headers)
+	}
+
+	var empty *EventMetadata
+	if empty.Merge(nil) != nil || empty.Merge(&EventMetadata{}) != nil {
+		t.Error("expected nil for empty metadata")
+	}
+}
+
+func TestEventMetadata_Validate(t *testing.T) {
+	valid := &EventMetadata{Headers: map[string]string{"request-origin": "web", "trace.v1": "x"}}
+	assertValid(t, valid.Validate())
+
+	for _, name := range []string{"Request-Origin", "has space", "event-type", "correlation-id", ""} {
+		m := &EventMetadata{Headers: map[string]string{name: "x"}}
+		assertValidationError(t, m.Validate())
+	}
+}
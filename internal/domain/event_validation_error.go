@@ -2,10 +2,24 @@ package domain
 
 import (
 	"fmt"
+	"strings"
 )
 
+// Violation — нарушение одного правила в одном поле события.
+type Violation struct {
+	Pointer  string      `json:"pointer"`            // JSON pointer на поле события, например /payload/status
+	Rule     string      `json:"rule"`               // нарушенное правило: required, enum, pattern, max_past, ...
+	Expected interface{} `json:"expected,omitempty"` // чего требует правило: тип, допустимые значения, предел
+	Message  string      `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Pointer, v.Message)
+}
+
 type EventValidationError struct {
-	Reason string
+	Reason     string
+	Violations []Violation // пусто, если ошибку нельзя привязать к полям
 }
 
 func (e *EventValidationError) Error() string {
@@ -15,3 +29,12 @@ func (e *EventValidationError) Error() string {
 func NewEventValidationError(reason string) *EventValidationError {
 	return &EventValidationError{Reason: reason}
 }
+
+// NewViolationError собирает ошибку валидации из нарушений; Reason перечисляет их через "; ".
+func NewViolationError(violations ...Violation) *EventValidationError {
+	reasons := make([]string, len(violations))
+	for i, v := range violations {
+		reasons[i] = v.String()
+	}
+	return &EventValidationError{Reason: strings.Join(reasons, "; "), Violations: violations}
+}
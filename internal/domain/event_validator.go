@@ -1,81 +1,528 @@
 package domain
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/linkedin/goavro/v2"
 	"github.com/xeipuuv/gojsonschema"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
 type EventValidator interface {
-	Validate(event *Event) error
+	Validate(ctx context.Context, event *Event) error
 }
 
 type JSONSchemaValidator struct {
-	schemas  map[string]*gojsonschema.Schema
+	mu       sync.RWMutex
+	set      *SchemaSet
 	registry EventRegistryInterface
 }
 type EventRegistryInterface interface {
 	ResolveChannel(channel string) (string, string, error)
+	// ResolveSchemaVersions возвращает версии схемы, которые принимает канал
+	// (пусто — любые загруженные), и версию по умолчанию (0 — не задана).
+	ResolveSchemaVersions(channel string) (accepted []int, defaultVersion int, err error)
+	// ResolveTimestampWindow возвращает допустимое отклонение timestamp событий канала.
+	ResolveTimestampWindow(channel string) (TimestampWindow, error)
+	// ResolvePayloadFormat возвращает формат payload канала и, для Protobuf, полное имя сообщения.
+	ResolvePayloadFormat(channel string) (format PayloadFormat, protoMessage string, err error)
+	// ResolvePartitionKey возвращает JSON pointer ключа партиции, если канал пишет в Kafka;
+	// пусто — ключ не нужен.
+	ResolvePartitionKey(channel string) (string, error)
+}
+
+// SchemaSet — набор схем из каталога: имя схемы -> версия -> схема. Все версии
+// одной схемы записаны в одном формате: JSON Schema, Avro или Protobuf.
+type SchemaSet struct {
+	schemas   map[string]map[int]*gojsonschema.Schema
+	avro      map[string]map[int]*goavro.Codec
+	protos    map[string]map[int]*protoregistry.Files
+	documents map[string]map[int][]byte
+	formats   map[string]PayloadFormat
+}
+
+// schemaFileRe разбирает имена файлов схем: name.schema.json (версия 1) и name.vN.schema.json;
+// так же версионируются Avro (name.avsc) и Protobuf (name.protoset — FileDescriptorSet).
+var schemaFileRe = regexp.MustCompile(`^(.+?)(?:\.v([0-9]+))?\.(schema\.json|avsc|protoset)$`)
+
+var schemaFileFormats = map[string]PayloadFormat{
+	"schema.json": PayloadFormatJSON,
+	"avsc":        PayloadFormatAvro,
+	"protoset":    PayloadFormatProtobuf,
 }
 
 func NewJSONSchemaValidator(schemaDir string, registry EventRegistryInterface) (*JSONSchemaValidator, error) {
-	schemas := make(map[string]*gojsonschema.Schema)
+	set, err := LoadSchemaSet(schemaDir)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONSchemaValidator{
+		set:      set,
+		registry: registry,
+	}, nil
+}
+
+// LoadSchemaSet загружает и компилирует все схемы каталога. Ошибки содержимого
+// файлов оборачивают ErrInvalidSchema, остальные — ошибки чтения каталога.
+func LoadSchemaSet(schemaDir string) (*SchemaSet, error) {
+	set := &SchemaSet{
+		schemas:   make(map[string]map[int]*gojsonschema.Schema),
+		avro:      make(map[string]map[int]*goavro.Codec),
+		protos:    make(map[string]map[int]*protoregistry.Files),
+		documents: make(map[string]map[int][]byte),
+		formats:   make(map[string]PayloadFormat),
+	}
 	files, err := os.ReadDir(schemaDir)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, file := range files {
-		if filepath.Ext(file.Name()) == ".json" {
-			schemaPath := filepath.Join(schemaDir, file.Name())
-			absPath, err := filepath.Abs(schemaPath)
-			if err != nil {
-				return nil, err
+		schemaName, version, format, ok := parseSchemaFileName(file.Name())
+		if !ok || file.IsDir() {
+			continue
+		}
+		schemaPath := filepath.Join(schemaDir, file.Name())
+		absPath, err := filepath.Abs(schemaPath)
+		if err != nil {
+			return nil, err
+		}
+		document, err := os.ReadFile(absPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema %s: %w", file.Name(), err)
+		}
+		if existing, ok := set.formats[schemaName]; ok && existing != format {
+			return nil, fmt.Errorf("%w: schema %s has both %s and %s versions in %s", ErrInvalidSchema, schemaName, existing, format, file.Name())
+		}
+		if _, exists := set.documents[schemaName][version]; exists {
+			return nil, fmt.Errorf("%w: duplicate schema %s version %d in %s", ErrInvalidSchema, schemaName, version, file.Name())
+		}
+		if err := set.compile(schemaName, version, format, absPath, document); err != nil {
+			return nil, fmt.Errorf("%w: failed to load schema %s: %v", ErrInvalidSchema, file.Name(), err)
+		}
+		set.formats[schemaName] = format
+		addSchemaVersion(set.documents, schemaName, version, document)
+	}
+	return set, nil
+}
+
+// compile разбирает документ схемы в валидатор ее формата.
+func (s *SchemaSet) compile(schemaName string, version int, format PayloadFormat, absPath string, document []byte) error {
+	switch format {
+	case PayloadFormatAvro:
+		codec, err := goavro.NewCodecForStandardJSONFull(string(document))
+		if err != nil {
+			return err
+		}
+		addSchemaVersion(s.avro, schemaName, version, codec)
+	case PayloadFormatProtobuf:
+		files, err := loadProtoset(document)
+		if err != nil {
+			return err
+		}
+		addSchemaVersion(s.protos, schemaName, version, files)
+	default:
+		u := &url.URL{
+			Scheme: "file",
+			Path:   "/" + filepath.ToSlash(absPath),
+		}
+		schemaLoader := gojsonschema.NewReferenceLoader(u.String())
+		schema, err := gojsonschema.NewSchema(schemaLoader)
+		if err != nil {
+			return err
+		}
+		addSchemaVersion(s.schemas, schemaName, version, schema)
+	}
+	return nil
+}
+
+func addSchemaVersion[T any](m map[string]map[int]T, schemaName string, version int, value T) {
+	if m[schemaName] == nil {
+		m[schemaName] = make(map[int]T)
+	}
+	m[schemaName][version] = value
+}
+
+// Versions возвращает загруженные версии схемы по возрастанию.
+func (s *SchemaSet) Versions(schemaName string) []int {
+	result := make([]int, 0, len(s.documents[schemaName]))
+	for version := range s.documents[schemaName] {
+		result = append(result, version)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// Document возвращает исходный документ схемы: JSON Schema, Avro-схему или FileDescriptorSet.
+func (s *SchemaSet) Document(schemaName string, version int) ([]byte, bool) {
+	doc, ok := s.documents[schemaName][version]
+	return doc, ok
+}
+
+// Format возвращает формат схемы; false — схема не загружена.
+func (s *SchemaSet) Format(schemaName string) (PayloadFormat, bool) {
+	format, ok := s.formats[schemaName]
+	return format, ok
+}
+
+// SchemaCheck — результат проверки совместимости одной замененной схемы.
+type SchemaCheck struct {
+	Schema  string                     `json:"schema"`
+	Version int                        `json:"version"`
+	Report  *SchemaCompatibilityReport `json:"report"`
+}
+
+// CheckCompatibility сравнивает каждую схему, которая есть в обоих наборах и изменилась.
+// Совместимость проверяется только для JSON Schema; смена формата схемы — breaking.
+func (s *SchemaSet) CheckCompatibility(next *SchemaSet) ([]SchemaCheck, error) {
+	var checks []SchemaCheck
+	for _, name := range sortedKeys(s.documents) {
+		for _, version := range s.Versions(name) {
+			oldDoc := s.documents[name][version]
+			newDoc, ok := next.Document(name, version)
+			if !ok || bytes.Equal(oldDoc, newDoc) {
+				continue
 			}
-			u := &url.URL{
-				Scheme: "file",
-				Path:   "/" + filepath.ToSlash(absPath),
+			if oldFormat, newFormat := s.formats[name], next.formats[name]; oldFormat != newFormat {
+				checks = append(checks, SchemaCheck{Schema: name, Version: version, Report: &SchemaCompatibilityReport{
+					Compatibility: CompatibilityBreaking,
+					Changes: []SchemaChange{{
+						Description:   fmt.Sprintf("format changed from %s to %s", oldFormat, newFormat),
+						Compatibility: CompatibilityBreaking,
+					}},
+				}})
+				continue
+			} else if oldFormat != PayloadFormatJSON {
+				continue
 			}
-			schemaLoader := gojsonschema.NewReferenceLoader(u.String())
-			schema, err := gojsonschema.NewSchema(schemaLoader)
+			report, err := CheckSchemaCompatibility(oldDoc, newDoc)
 			if err != nil {
-				return nil, fmt.Errorf("failed to load schema %s: %w", file.Name(), err)
+				return nil, fmt.Errorf("schema %s version %d: %w", name, version, err)
 			}
-			schemaType := file.Name()[:len(file.Name())-len(".schema.json")]
-			schemas[schemaType] = schema
+			checks = append(checks, SchemaCheck{Schema: name, Version: version, Report: report})
 		}
 	}
-	return &JSONSchemaValidator{
-		schemas:  schemas,
-		registry: registry,
-	}, nil
+	return checks, nil
+}
+
+// SchemaDiff — какие схемы (в виде "name vN") добавлены, изменены или удалены.
+type SchemaDiff struct {
+	Added   []string `json:"added"`
+	Changed []string `json:"changed"`
+	Removed []string `json:"removed"`
+}
+
+// Diff сравнивает текущий набор с next.
+func (s *SchemaSet) Diff(next *SchemaSet) SchemaDiff {
+	diff := SchemaDiff{Added: []string{}, Changed: []string{}, Removed: []string{}}
+	for _, name := range sortedKeys(next.documents) {
+		for _, version := range next.Versions(name) {
+			oldDoc, ok := s.Document(name, version)
+			switch {
+			case !ok:
+				diff.Added = append(diff.Added, fmt.Sprintf("%s v%d", name, version))
+			case !bytes.Equal(oldDoc, next.documents[name][version]):
+				diff.Changed = append(diff.Changed, fmt.Sprintf("%s v%d", name, version))
+			}
+		}
+	}
+	for _, name := range sortedKeys(s.documents) {
+		for _, version := range s.Versions(name) {
+			if _, ok := next.Document(name, version); !ok {
+				diff.Removed = append(diff.Removed, fmt.Sprintf("%s v%d", name, version))
+			}
+		}
+	}
+	return diff
+}
+
+// SchemaCompatibilityError возвращается, когда перезагрузка отклонена из-за breaking изменений.
+type SchemaCompatibilityError struct {
+	Checks []SchemaCheck
 }
 
-func (v *JSONSchemaValidator) Validate(event *Event) error {
+func (e *SchemaCompatibilityError) Error() string {
+	var names []string
+	for _, check := range e.Checks {
+		names = append(names, fmt.Sprintf("%s v%d", check.Schema, check.Version))
+	}
+	return fmt.Sprintf("breaking schema changes in %s (use force to apply)", strings.Join(names, ", "))
+}
+
+// ReloadSchemas перечитывает каталог схем. Изменения, отклоняющие уже валидные
+// документы, применяются только при force.
+func (v *JSONSchemaValidator) ReloadSchemas(schemaDir string, force bool) ([]SchemaCheck, error) {
+	next, err := LoadSchemaSet(schemaDir)
+	if err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	checks, err := v.set.CheckCompatibility(next)
+	if err != nil {
+		return nil, err
+	}
+	if err := RefuseBreaking(checks, force); err != nil {
+		return checks, err
+	}
+	v.set = next
+	return checks, nil
+}
+
+// RefuseBreaking возвращает SchemaCompatibilityError, если среди проверок есть breaking и не задан force.
+func RefuseBreaking(checks []SchemaCheck, force bool) error {
+	if force {
+		return nil
+	}
+	var breaking []SchemaCheck
+	for _, check := range checks {
+		if check.Report.Breaking() {
+			breaking = append(breaking, check)
+		}
+	}
+	if len(breaking) > 0 {
+		return &SchemaCompatibilityError{Checks: breaking}
+	}
+	return nil
+}
+
+// SwapSchemas атомарно заменяет набор схем. alongside (например, замена каналов в registry)
+// выполняется под той же блокировкой, так что Validate не увидит новые каналы со старыми схемами.
+func (v *JSONSchemaValidator) SwapSchemas(next *SchemaSet, alongside func()) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.set = next
+	if alongside != nil {
+		alongside()
+	}
+}
+
+// Schemas возвращает текущий набор схем.
+func (v *JSONSchemaValidator) Schemas() *SchemaSet {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.set
+}
+
+// parseSchemaFileName возвращает имя схемы, версию и формат из имени файла.
+func parseSchemaFileName(fileName string) (string, int, PayloadFormat, bool) {
+	m := schemaFileRe.FindStringSubmatch(fileName)
+	if m == nil {
+		return "", 0, "", false
+	}
+	version := 1
+	if m[2] != "" {
+		v, err := strconv.Atoi(m[2])
+		if err != nil || v < 1 {
+			return "", 0, "", false
+		}
+		version = v
+	}
+	return m[1], version, schemaFileFormats[m[3]], true
+}
+
+type skipTimestampWindowKey struct{}
+
+// WithoutTimestampWindow отключает в Validate проверку окна timestamp канала. Окно
+// ограничивает только прием: при потреблении и отыгрывании dead letters событие
+// законно старше max_past и не должно отбрасываться из-за возраста.
+func WithoutTimestampWindow(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipTimestampWindowKey{}, true)
+}
+
+// Validate проверяет формат ID и заголовков метаданных, окно timestamp канала и payload по версии схемы,
+// указанной в событии (или версии канала по умолчанию), и проставляет итоговую
+// версию в event.SchemaVersion. Пустые ID и timestamp не проверяются — их
+// заполняет прием (Event.ApplyDefaults); окно не проверяется для ctx из WithoutTimestampWindow.
+// Отмененный ctx прерывает проверку до ее начала.
+func (v *JSONSchemaValidator) Validate(ctx context.Context, event *Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if event.ID != "" {
+		if err := ValidateEventID(event.ID); err != nil {
+			return err
+		}
+	}
+	if err := event.Metadata.Validate(); err != nil {
+		return err
+	}
+
+	// Блокировка держится на время всей проверки, чтобы каналы и схемы были из одной перезагрузки
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	ref, err := v.resolveSchema(v.set, event)
+	if err != nil {
+		return err
+	}
+
+	if skip, _ := ctx.Value(skipTimestampWindowKey{}).(bool); !skip && !event.Timestamp.IsZero() {
+		window, err := v.registry.ResolveTimestampWindow(event.Type)
+		if err != nil {
+			return err
+		}
+		if err := window.Check(event.Timestamp, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	if err := v.set.validatePayload(ref, event.Payload); err != nil {
+		return err
+	}
+
+	// Ключ партиции проверяется при приеме, до записи в outbox: иначе событие
+	// было бы принято и отклонено только при публикации в Kafka
+	pointer, err := v.registry.ResolvePartitionKey(event.Type)
+	if err != nil {
+		return err
+	}
+	if _, err := PartitionKey(pointer, event); err != nil {
+		return err
+	}
+	event.SchemaVersion = ref.version
+	return nil
+}
+
+// schemaRef — схема, по которой проверяется и кодируется payload события.
+type schemaRef struct {
+	name    string
+	version int
+	format  PayloadFormat
+	message string // полное имя сообщения для Protobuf
+}
+
+// resolveSchema выбирает схему канала и версию: из события, иначе версию канала
+// по умолчанию, иначе самую старую из принимаемых. Формат схемы должен совпадать с форматом канала.
+func (v *JSONSchemaValidator) resolveSchema(set *SchemaSet, event *Event) (schemaRef, error) {
 	_, schemaName, err := v.registry.ResolveChannel(event.Type)
-	schema, ok := v.schemas[schemaName]
-	if !ok {
-		return fmt.Errorf("no schema '%s' for event type: %s", schemaName, event.Type)
+	if err != nil {
+		return schemaRef{}, err
+	}
+	format, message, err := v.registry.ResolvePayloadFormat(event.Type)
+	if err != nil {
+		return schemaRef{}, err
+	}
+	if f, ok := set.formats[schemaName]; !ok || f != format {
+		return schemaRef{}, fmt.Errorf("%w: no %s schema '%s' for event type: %s", ErrSchemaNotFound, format, schemaName, event.Type)
+	}
+
+	accepted, defaultVersion, err := v.registry.ResolveSchemaVersions(event.Type)
+	if err != nil {
+		return schemaRef{}, err
+	}
+	if len(accepted) == 0 {
+		accepted = set.Versions(schemaName)
+	}
+
+	version := event.SchemaVersion
+	if version == 0 {
+		version = defaultVersion
+	}
+	if version == 0 {
+		// Событие без версии написано под исходную схему — берем самую старую из принимаемых
+		version = accepted[0]
+		for _, a := range accepted {
+			if a < version {
+				version = a
+			}
+		}
+	}
+	if !slices.Contains(accepted, version) {
+		return schemaRef{}, NewViolationError(Violation{
+			Pointer:  "/schema_version",
+			Rule:     "enum",
+			Expected: accepted,
+			Message:  fmt.Sprintf("schema version %d is not accepted for event type %s (accepted: %v)", version, event.Type, accepted),
+		})
 	}
+	if _, ok := set.documents[schemaName][version]; !ok {
+		return schemaRef{}, fmt.Errorf("%w: no schema '%s' version %d for event type: %s", ErrSchemaNotFound, schemaName, version, event.Type)
+	}
+	return schemaRef{name: schemaName, version: version, format: format, message: message}, nil
+}
 
-	loader := gojsonschema.NewGoLoader(event.Payload)
-	result, err := schema.Validate(loader)
+// validatePayload проверяет payload по схеме в ее формате.
+func (s *SchemaSet) validatePayload(ref schemaRef, payload map[string]interface{}) error {
+	switch ref.format {
+	case PayloadFormatAvro, PayloadFormatProtobuf:
+		_, err := s.encodePayload(ref, payload)
+		return payloadViolation(ref, err)
+	}
+
+	result, err := s.schemas[ref.name][ref.version].Validate(gojsonschema.NewGoLoader(payload))
 	if err != nil {
 		return err
 	}
 	if !result.Valid() {
-		var reasons []string
-		for _, err := range result.Errors() {
-			reasons = append(reasons, err.String())
-		}
-		return NewEventValidationError(strings.Join(reasons, "; "))
+		return NewViolationError(schemaViolations(result.Errors())...)
 	}
 	return nil
 }
 
-// func (v *JSONSchemaValidator) ReloadSchemas(schemaDir string) error {
-// }
+// schemaExpectedDetails — поля деталей gojsonschema, описывающие требование правила, в порядке приоритета.
+var schemaExpectedDetails = []string{"expected", "allowed", "min", "max", "pattern", "format", "multiple"}
+
+// schemaViolations переводит ошибки gojsonschema в нарушения с указателями от корня события.
+func schemaViolations(errs []gojsonschema.ResultError) []Violation {
+	violations := make([]Violation, 0, len(errs))
+	for _, err := range errs {
+		// Контекст имеет вид (root).a.b; разделитель \x00 не встречается в именах полей JSON
+		tokens := strings.Split(err.Context().String("\x00"), "\x00")[1:]
+		details := err.Details()
+		if property, ok := details["property"].(string); ok && err.Type() == "required" {
+			tokens = append(tokens, property)
+		}
+		pointer := "/payload"
+		for _, token := range tokens {
+			pointer += "/" + escapePointer(token)
+		}
+
+		v := Violation{Pointer: pointer, Rule: err.Type(), Message: err.Description()}
+		for _, key := range schemaExpectedDetails {
+			if expected, ok := details[key]; ok {
+				v.Expected = schemaExpectedValue(v.Rule, expected)
+				break
+			}
+		}
+		violations = append(violations, v)
+	}
+	return violations
+}
+
+// schemaExpectedValue приводит детали gojsonschema к JSON-значениям: enum приходит
+// строкой из JSON-значений через запятую, числовые пределы — *big.Float.
+func schemaExpectedValue(rule string, expected interface{}) interface{} {
+	switch e := expected.(type) {
+	case *big.Float:
+		f, _ := e.Float64()
+		return f
+	case string:
+		var values []interface{}
+		if rule == "enum" && json.Unmarshal([]byte("["+e+"]"), &values) == nil {
+			return values
+		}
+	}
+	return expected
+}
+
+// SchemaVersions возвращает загруженные версии схемы по возрастанию.
+func (v *JSONSchemaValidator) SchemaVersions(schemaName string) []int {
+	return v.Schemas().Versions(schemaName)
+}
@@ -0,0 +1,271 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONSchemaValidator_DefaultsToOldestVersion(t *testing.T) {
+	validator := setupVersionedValidator(t, fakeRegistry{})
+
+	event := &Event{Type: "OrderStatusEvent", Payload: map[string]interface{}{"order_id": "1"}}
+	assertValid(t, validator.Validate(context.Background(), event))
+
+	if event.SchemaVersion != 1 {
+		t.Errorf("expected event stamped with version 1, got %d", event.SchemaVersion)
+	}
+}
+
+func TestJSONSchemaValidator_ValidatesRequestedVersion(t *testing.T) {
+	validator := setupVersionedValidator(t, fakeRegistry{})
+
+	// v2 additionally requires "status"
+	event := &Event{Type: "OrderStatusEvent", SchemaVersion: 2, Payload: map[string]interface{}{"order_id": "1"}}
+	assertValidationError(t, validator.Validate(context.Background(), event))
+
+	event.Payload["status"] = "packed"
+	assertValid(t, validator.Validate(context.Background(), event))
+}
+
+func TestJSONSchemaValidator_UsesChannelDefaultVersion(t *testing.T) {
+	validator := setupVersionedValidator(t, fakeRegistry{versions: []int{1, 2}, defaultVersion: 2})
+
+	event := &Event{Type: "OrderStatusEvent", Payload: map[string]interface{}{"order_id": "1", "status": "packed"}}
+	assertValid(t, validator.Validate(context.Background(), event))
+
+	if event.SchemaVersion != 2 {
+		t.Errorf("expected event stamped with version 2, got %d", event.SchemaVersion)
+	}
+}
+
+func TestJSONSchemaValidator_RejectsVersionNotAcceptedByChannel(t *testing.T) {
+	validator := setupVersionedValidator(t, fakeRegistry{versions: []int{2}})
+
+	event := &Event{Type: "OrderStatusEvent", SchemaVersion: 1, Payload: map[string]interface{}{"order_id": "1"}}
+	assertValidationError(t, validator.Validate(context.Background(), event))
+}
+
+func TestJSONSchemaValidator_LoadsVersionsSideBySide(t *testing.T) {
+	validator := setupVersionedValidator(t, fakeRegistry{})
+
+	versions := validator.SchemaVersions("order_status_notification")
+	if fmt.Sprint(versions) != "[1 2]" {
+		t.Errorf("expected versions [1 2], got %v", versions)
+	}
+}
+
+func TestJSONSchemaValidator_ReloadSchemas_RefusesBreakingChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeSchemaFile(t, dir, "order_status_notification.schema.json", `{"type": "object", "required": ["order_id"]}`)
+	validator, err := NewJSONSchemaValidator(dir, fakeRegistry{schemaName: "order_status_notification"})
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	writeSchemaFile(t, dir, "order_status_notification.schema.json", `{"type": "object", "required": ["order_id", "status"]}`)
+
+	_, err = validator.ReloadSchemas(dir, false)
+	var compatErr *SchemaCompatibilityError
+	if !errors.As(err, &compatErr) {
+		t.Fatalf("expected SchemaCompatibilityError, got %v", err)
+	}
+	event := &Event{Type: "OrderStatusEvent", Payload: map[string]interface{}{"order_id": "1"}}
+	assertValid(t, validator.Validate(context.Background(), event))
+
+	if _, err := validator.ReloadSchemas(dir, true); err != nil {
+		t.Fatalf("expected forced reload to succeed, got %v", err)
+	}
+	event.SchemaVersion = 0
+	assertValidationError(t, validator.Validate(context.Background(), event))
+}
+
+func TestJSONSchemaValidator_RejectsMalformedEventID(t *testing.T) {
+	validator := setupVersionedValidator(t, fakeRegistry{})
+
+	for _, id := range []string{"test-event-123", "0f8fad5b-d9cb-469f-a165-70867728950e", "01HZY3J6Q8V0Z2:order.1_a"} {
+		event := &Event{ID: id, Type: "OrderStatusEvent", Payload: map[string]interface{}{"order_id": "1"}}
+		assertValid(t, validator.Validate(context.Background(), event))
+	}
+	for _, id := range []string{"has space", "../etc/passwd", "-leading-dash", strings.Repeat("a", 129)} {
+		event := &Event{ID: id, Type: "OrderStatusEvent", Payload: map[string]interface{}{"order_id": "1"}}
+		assertValidationError(t, validator.Validate(context.Background(), event))
+	}
+}
+
+func TestJSONSchemaValidator_ChecksTimestampWindow(t *testing.T) {
+	validator := setupVersionedValidator(t, fakeRegistry{window: TimestampWindow{MaxPast: time.Hour, MaxFuture: time.Minute}})
+	newEvent := func(ts time.Time) *Event {
+		return &Event{Type: "OrderStatusEvent", Timestamp: ts, Payload: map[string]interface{}{"order_id": "1"}}
+	}
+
+	assertValid(t, validator.Validate(context.Background(), newEvent(time.Now().Add(-30*time.Minute))))
+	assertValidationError(t, validator.Validate(context.Background(), newEvent(time.Now().Add(-2*time.Hour))))
+	assertValidationError(t, validator.Validate(context.Background(), newEvent(time.Now().Add(10*time.Minute))))
+}
+
+func TestJSONSchemaValidator_WithoutTimestampWindowSkipsWindow(t *testing.T) {
+	validator := setupVersionedValidator(t, fakeRegistry{window: TimestampWindow{MaxPast: time.Hour}})
+	event := &Event{Type: "OrderStatusEvent", Timestamp: time.Now().Add(-48 * time.Hour), Payload: map[string]interface{}{"order_id": "1"}}
+
+	assertValid(t, validator.Validate(WithoutTimestampWindow(context.Background()), event))
+}
+
+func TestJSONSchemaValidator_ReportsViolations(t *testing.T) {
+	dir := t.TempDir()
+	writeSchemaFile(t, dir, "order_status_notification.schema.json", `{
+		"type": "object",
+		"required": ["order_id", "status"],
+		"properties": {
+			"status": {"enum": ["pending", "packed"]},
+			"customer": {"type": "object", "properties": {"a/b": {"type": "integer", "minimum": 1}}}
+		}
+	}`)
+	validator, err := NewJSONSchemaValidator(dir, fakeRegistry{schemaName: "order_status_notification"})
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+
+	event := &Event{Type: "OrderStatusEvent", Payload: map[string]interface{}{
+		"status":   "lost",
+		"customer": map[string]interface{}{"a/b": 0},
+	}}
+	err = validator.Validate(context.Background(), event)
+	var validationErr *EventValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	byPointer := make(map[string]Violation)
+	for _, v := range validationErr.Violations {
+		byPointer[v.Pointer] = v
+	}
+	if v := byPointer["/payload/order_id"]; v.Rule != "required" || v.Message == "" {
+		t.Errorf("expected required violation for order_id, got %+v", v)
+	}
+	if v := byPointer["/payload/status"]; v.Rule != "enum" || fmt.Sprint(v.Expected) != "[pending packed]" {
+		t.Errorf("expected enum violation with allowed values, got %+v", v)
+	}
+	if v := byPointer["/payload/customer/a~1b"]; v.Rule != "number_gte" || v.Expected != 1.0 {
+		t.Errorf("expected minimum violation with escaped pointer, got %+v", v)
+	}
+	if len(validationErr.Violations) != 3 {
+		t.Errorf("expected 3 violations, got %+v", validationErr.Violations)
+	}
+}
+
+func TestJSONSchemaValidator_RequiresPartitionKey(t *testing.T) {
+	validator := setupVersionedValidator(t, fakeRegistry{partitionKey: "/customer_id"})
+
+	event := &Event{Type: "OrderStatusEvent", Payload: map[string]interface{}{"order_id": "1"}}
+	if err := validator.Validate(context.Background(), event); !errors.Is(err, ErrMissingPartitionKey) {
+		t.Fatalf("expected ErrMissingPartitionKey, got %v", err)
+	}
+
+	event.Payload["customer_id"] = "c-1"
+	assertValid(t, validator.Validate(context.Background(), event))
+}
+
+func TestJSONSchemaValidator_MissingSchema(t *testing.T) {
+	validator := setupVersionedValidator(t, fakeRegistry{versions: []int{1, 3}})
+
+	event := &Event{Type: "OrderStatusEvent", SchemaVersion: 3, Payload: map[string]interface{}{"order_id": "1"}}
+	if err := validator.Validate(context.Background(), event); !errors.Is(err, ErrSchemaNotFound) {
+		t.Errorf("expected ErrSchemaNotFound for unloaded version, got %v", err)
+	}
+}
+
+func TestEvent_ApplyDefaults(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*3600))
+
+	event := &Event{Type: "OrderStatusEvent"}
+	event.ApplyDefaults(now)
+	if event.ID == "" || !event.Timestamp.Equal(now) || event.Timestamp.Location() != time.UTC {
+		t.Errorf("expected generated ID and UTC receive time, got %q at %v", event.ID, event.Timestamp)
+	}
+
+	given := time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)
+	event = &Event{ID: "order-1", Timestamp: given}
+	event.ApplyDefaults(now)
+	if event.ID != "order-1" || !event.Timestamp.Equal(given) {
+		t.Errorf("client values must be kept, got %q at %v", event.ID, event.Timestamp)
+	}
+}
+
+// === Test Helpers ===
+
+func setupVersionedValidator(t *testing.T, registry fakeRegistry) *JSONSchemaValidator {
+	dir := t.TempDir()
+	writeSchemaFile(t, dir, "order_status_notification.schema.json",
+		`{"type": "object", "required": ["order_id"]}`)
+	writeSchemaFile(t, dir, "order_status_notification.v2.schema.json",
+		`{"type": "object", "required": ["order_id", "status"]}`)
+
+	registry.schemaName = "order_status_notification"
+	validator, err := NewJSONSchemaValidator(dir, registry)
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	return validator
+}
+
+func writeSchemaFile(t *testing.T, dir, name, content string) {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write schema %s: %v", name, err)
+	}
+}
+
+func assertValid(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("expected event to be valid, got %v", err)
+	}
+}
+
+func assertValidationError(t *testing.T, err error) {
+	t.Helper()
+	var validationErr *EventValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+// === Fake Registry ===
+
+type fakeRegistry struct {
+	schemaName     string
+	versions       []int
+	defaultVersion int
+	window         TimestampWindow
+	format         PayloadFormat
+	protoMessage   string
+	partitionKey   string
+}
+
+func (r fakeRegistry) ResolveChannel(channel string) (string, string, error) {
+	return "orders-topic", r.schemaName, nil
+}
+
+func (r fakeRegistry) ResolveSchemaVersions(channel string) ([]int, int, error) {
+	return r.versions, r.defaultVersion, nil
+}
+
+func (r fakeRegistry) ResolveTimestampWindow(channel string) (TimestampWindow, error) {
+	return r.window, nil
+}
+
+func (r fakeRegistry) ResolvePayloadFormat(channel string) (PayloadFormat, string, error) {
+	if r.format == "" {
+		return PayloadFormatJSON, "", nil
+	}
+	return r.format, r.protoMessage, nil
+}
+
+func (r fakeRegistry) ResolvePartitionKey(channel string) (string, error) {
+	return r.partitionKey, nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrDuplicateInProgress — событие с этим ключом сейчас обрабатывается другим запросом.
+	ErrDuplicateInProgress = errors.New("event with this idempotency key is being processed")
+	// ErrIdempotencyKeyReused — ключ уже использован для события с другим содержимым.
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with different event content")
+)
+
+// IdempotencyRecord — запомненный прием события. Пока событие обрабатывается,
+// AcceptedAt пустой.
+type IdempotencyRecord struct {
+	Key         string
+	EventID     string
+	Fingerprint string // хеш типа и payload: отличает повтор от другого события с тем же ключом
+	AcceptedAt  time.Time
+}
+
+func (r *IdempotencyRecord) Pending() bool {
+	return r.AcceptedAt.IsZero()
+}
+
+// IdempotencyStore помнит принятые события в течение окна дедупликации.
+type IdempotencyStore interface {
+	// Reserve занимает ключ записи. Если ключ уже занят и не истек, возвращает
+	// существующую запись и false.
+	Reserve(ctx context.Context, record IdempotencyRecord) (*IdempotencyRecord, bool, error)
+	// Complete отмечает событие принятым; с этого момента отсчитывается окно.
+	Complete(ctx context.Context, key string, acceptedAt time.Time) error
+	// Release освобождает ключ после неудачной обработки, чтобы повтор прошел заново.
+	Release(ctx context.Context, key string) error
+}
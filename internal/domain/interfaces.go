@@ -1,9 +1,26 @@
 package domain
 
+import "context"
+
+// EventPublisher доставляет событие. Отмена ctx прерывает доставку.
 type EventPublisher interface {
-	Publish(event *Event) error
+	Publish(ctx context.Context, event *Event) error
+}
+
+// BatchPublisher — publisher, который доставляет несколько событий за один вызов.
+// Возвращает ошибку по каждому событию в том же порядке (nil — доставлено).
+type BatchPublisher interface {
+	PublishBatch(ctx context.Context, events []*Event) []error
+}
+
+// TransactionalPublisher сохраняет набор событий атомарно: либо все, либо ни одного.
+type TransactionalPublisher interface {
+	PublishAll(ctx context.Context, events []*Event) error
 }
 
+// EventHandler обрабатывает события, прочитанные консьюмером. Если любой обработчик
+// типа вернул ошибку, событие доставляется повторно всем обработчикам этого типа,
+// поэтому Handle должен быть идемпотентным, например по event.ID.
 type EventHandler interface {
-	Handle(event *Event) error
+	Handle(ctx context.Context, event *Event) error
 }
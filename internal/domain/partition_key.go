@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PartitionKey возвращает ключ сообщения по JSON pointer в payload события.
+// Строки используются как есть, числа и bool — в JSON-представлении.
+// Пустой pointer означает, что ключ не нужен (nil).
+func PartitionKey(pointer string, event *Event) ([]byte, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	var current interface{} = event.Payload
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("event %s (%s): %w: %s", event.ID, event.Type, ErrMissingPartitionKey, pointer)
+			}
+			current = value
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("event %s (%s): %w: %s", event.ID, event.Type, ErrMissingPartitionKey, pointer)
+			}
+			current = node[i]
+		default:
+			return nil, fmt.Errorf("event %s (%s): %w: %s", event.ID, event.Type, ErrMissingPartitionKey, pointer)
+		}
+	}
+
+	switch value := current.(type) {
+	case nil:
+		return nil, fmt.Errorf("event %s (%s): %w: %s is null", event.ID, event.Type, ErrMissingPartitionKey, pointer)
+	case string:
+		if value == "" {
+			return nil, fmt.Errorf("event %s (%s): %w: %s is empty", event.ID, event.Type, ErrMissingPartitionKey, pointer)
+		}
+		return []byte(value), nil
+	case map[string]interface{}, []interface{}:
+		return nil, fmt.Errorf("event %s (%s): %w: %s must be a string, number or boolean", event.ID, event.Type, ErrMissingPartitionKey, pointer)
+	default:
+		return json.Marshal(value)
+	}
+}
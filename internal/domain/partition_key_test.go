@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPartitionKey(t *testing.T) {
+	event := NewEvent("OrderStatusEvent", map[string]interface{}{
+		"order_id": "12345",
+		"customer": map[string]interface{}{"id": float64(42), "a/b": "slash"},
+		"items":    []interface{}{map[string]interface{}{"sku": "X1"}},
+	})
+
+	cases := []struct {
+		pointer  string
+		expected string
+		missing  bool
+	}{
+		{"", "", false},
+		{"/order_id", "12345", false},
+		{"/customer/id", "42", false},
+		{"/customer/a~1b", "slash", false},
+		{"/items/0/sku", "X1", false},
+		{"/items/1/sku", "", true},
+		{"/customer/name", "", true},
+		{"/order_id/nested", "", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.pointer, func(t *testing.T) {
+			key, err := PartitionKey(tc.pointer, event)
+			if tc.missing {
+				if !errors.Is(err, ErrMissingPartitionKey) {
+					t.Fatalf("expected ErrMissingPartitionKey, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(key) != tc.expected {
+				t.Errorf("expected key %q, got %q", tc.expected, key)
+			}
+		})
+	}
+
+	if _, err := PartitionKey("/customer", event); !errors.Is(err, ErrMissingPartitionKey) {
+		t.Errorf("expected ErrMissingPartitionKey for object partition key, got %v", err)
+	}
+}
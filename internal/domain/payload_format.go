@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// PayloadFormat — формат payload канала: определяет тип схемы, по которой проверяется
+// событие, и кодирование сообщения при публикации в Kafka.
+type PayloadFormat string
+
+const (
+	PayloadFormatJSON     PayloadFormat = "json"     // JSON Schema, сообщение — JSON события
+	PayloadFormatAvro     PayloadFormat = "avro"     // схема .avsc, сообщение — Avro binary payload
+	PayloadFormatProtobuf PayloadFormat = "protobuf" // FileDescriptorSet .protoset, сообщение — Protobuf binary payload
+)
+
+// EncodedPayload — payload события, закодированный по схеме канала.
+type EncodedPayload struct {
+	Format   PayloadFormat
+	Version  int    // версия схемы, по которой закодирован payload
+	SchemaID string // имя и версия схемы, как в именах файлов: order_status.v2
+	Message  string // полное имя сообщения Protobuf
+	Data     []byte
+}
+
+// payloadFormatError — payload не соответствует Avro- или Protobuf-схеме.
+type payloadFormatError struct {
+	err error
+}
+
+func (e *payloadFormatError) Error() string {
+	return e.err.Error()
+}
+
+// payloadViolation переводит payloadFormatError в ошибку валидации; Avro и Protobuf
+// сообщают об ошибке одной строкой, поэтому нарушение указывает на весь payload.
+func payloadViolation(ref schemaRef, err error) error {
+	var violation *payloadFormatError
+	if errors.As(err, &violation) {
+		return NewViolationError(Violation{Pointer: "/payload", Rule: string(ref.format) + "_schema", Message: violation.Error()})
+	}
+	return err
+}
+
+// EncodePayload кодирует payload события в формат его канала по версии схемы
+// события (Validate проставляет ее заранее). JSON-каналы получают JSON payload.
+func (v *JSONSchemaValidator) EncodePayload(event *Event) (EncodedPayload, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	ref, err := v.resolveSchema(v.set, event)
+	if err != nil {
+		return EncodedPayload{}, err
+	}
+	data, err := v.set.encodePayload(ref, event.Payload)
+	if err != nil {
+		// Несоответствие схеме — ошибка валидации: повтор публикации не поможет
+		return EncodedPayload{}, fmt.Errorf("failed to encode payload of event %s as %s: %w", event.ID, ref.format, payloadViolation(ref, err))
+	}
+	return EncodedPayload{
+		Format:   ref.format,
+		Version:  ref.version,
+		SchemaID: fmt.Sprintf("%s.v%d", ref.name, ref.version),
+		Message:  ref.message,
+		Data:     data,
+	}, nil
+}
+
+// DecodePayload восстанавливает payload из сообщения, закодированного EncodePayload
+// по версии schemaVersion схемы канала eventType. Допустимость версии каналом не
+// проверяется: в топике могут лежать события версий, которые канал уже не принимает.
+func (v *JSONSchemaValidator) DecodePayload(eventType string, schemaVersion int, data []byte) (map[string]interface{}, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	_, schemaName, err := v.registry.ResolveChannel(eventType)
+	if err != nil {
+		return nil, err
+	}
+	format, message, err := v.registry.ResolvePayloadFormat(eventType)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := v.set.documents[schemaName][schemaVersion]; !ok || v.set.formats[schemaName] != format {
+		return nil, fmt.Errorf("%w: no %s schema '%s' version %d for event type: %s", ErrSchemaNotFound, format, schemaName, schemaVersion, eventType)
+	}
+	return v.set.decodePayload(schemaRef{name: schemaName, version: schemaVersion, format: format, message: message}, data)
+}
+
+func (s *SchemaSet) encodePayload(ref schemaRef, payload map[string]interface{}) ([]byte, error) {
+	text, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ref.format {
+	case PayloadFormatAvro:
+		codec := s.avro[ref.name][ref.version]
+		native, _, err := codec.NativeFromTextual(text)
+		if err != nil {
+			return nil, &payloadFormatError{err}
+		}
+		return codec.BinaryFromNative(nil, native)
+	case PayloadFormatProtobuf:
+		desc, err := s.protoMessage(ref)
+		if err != nil {
+			return nil, err
+		}
+		msg := dynamicpb.NewMessage(desc)
+		if err := protojson.Unmarshal(text, msg); err != nil {
+			return nil, &payloadFormatError{err}
+		}
+		return proto.Marshal(msg)
+	}
+	return text, nil
+}
+
+func (s *SchemaSet) decodePayload(ref schemaRef, data []byte) (map[string]interface{}, error) {
+	text := data
+	switch ref.format {
+	case PayloadFormatAvro:
+		codec := s.avro[ref.name][ref.version]
+		native, _, err := codec.NativeFromBinary(data)
+		if err != nil {
+			return nil, err
+		}
+		if text, err = codec.TextualFromNative(nil, native); err != nil {
+			return nil, err
+		}
+	case PayloadFormatProtobuf:
+		desc, err := s.protoMessage(ref)
+		if err != nil {
+			return nil, err
+		}
+		msg := dynamicpb.NewMessage(desc)
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return nil, err
+		}
+		if text, err = (protojson.MarshalOptions{UseProtoNames: true}).Marshal(msg); err != nil {
+			return nil, err
+		}
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(text, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// protoMessage находит описание сообщения канала в FileDescriptorSet схемы.
+func (s *SchemaSet) protoMessage(ref schemaRef) (protoreflect.MessageDescriptor, error) {
+	desc, err := s.protos[ref.name][ref.version].FindDescriptorByName(protoreflect.FullName(ref.message))
+	if err != nil {
+		return nil, fmt.Errorf("%w: no message %s in schema '%s' version %d", ErrSchemaNotFound, ref.message, ref.name, ref.version)
+	}
+	msg, ok := desc.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s in schema '%s' version %d is not a message", ErrSchemaNotFound, ref.message, ref.name, ref.version)
+	}
+	return msg, nil
+}
+
+// loadProtoset разбирает FileDescriptorSet (protoc --include_imports --descriptor_set_out).
+func loadProtoset(document []byte) (*protoregistry.Files, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(document, &set); err != nil {
+		return nil, fmt.Errorf("invalid FileDescriptorSet: %w", err)
+	}
+	return protodesc.NewFiles(&set)
+}
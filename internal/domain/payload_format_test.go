@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestJSONSchemaValidator_AvroPayload(t *testing.T) {
+	dir := t.TempDir()
+	writeSchemaFile(t, dir, "orders.avsc", `{
+		"type": "record", "name": "Order",
+		"fields": [
+			{"name": "order_id", "type": "string"},
+			{"name": "quantity", "type": "int"},
+			{"name": "note", "type": ["null", "string"], "default": null}
+		]
+	}`)
+	validator := newPayloadFormatValidator(t, dir, fakeRegistry{schemaName: "orders", format: PayloadFormatAvro})
+
+	event := &Event{ID: "evt-1", Type: "OrderEvent", Payload: map[string]interface{}{"order_id": "o-1", "quantity": 3, "note": "gift"}}
+	assertValid(t, validator.Validate(context.Background(), event))
+
+	encoded, err := validator.EncodePayload(event)
+	assertNoError(t, err)
+	if encoded.Format != PayloadFormatAvro || encoded.SchemaID != "orders.v1" || encoded.Version != 1 {
+		t.Errorf("unexpected encoded payload: %+v", encoded)
+	}
+	decoded, err := validator.DecodePayload("OrderEvent", 1, encoded.Data)
+	assertNoError(t, err)
+	if decoded["order_id"] != "o-1" || decoded["quantity"] != 3.0 || decoded["note"] != "gift" {
+		t.Errorf("payload did not survive the round trip: %v", decoded)
+	}
+
+	event.Payload["quantity"] = "three"
+	assertPayloadViolation(t, validator.Validate(context.Background(), event), "avro_schema")
+}
+
+func TestJSONSchemaValidator_ProtobufPayload(t *testing.T) {
+	dir := t.TempDir()
+	writeOrderProtoset(t, filepath.Join(dir, "orders.protoset"))
+	validator := newPayloadFormatValidator(t, dir, fakeRegistry{schemaName: "orders", format: PayloadFormatProtobuf, protoMessage: "orders.OrderStatus"})
+
+	event := &Event{ID: "evt-1", Type: "OrderEvent", Payload: map[string]interface{}{"order_id": "o-1", "quantity": 3}}
+	assertValid(t, validator.Validate(context.Background(), event))
+
+	encoded, err := validator.EncodePayload(event)
+	assertNoError(t, err)
+	if encoded.Message != "orders.OrderStatus" {
+		t.Errorf("expected message name in encoded payload, got %+v", encoded)
+	}
+	decoded, err := validator.DecodePayload("OrderEvent", 1, encoded.Data)
+	assertNoError(t, err)
+	if decoded["order_id"] != "o-1" || decoded["quantity"] != 3.0 {
+		t.Errorf("payload did not survive the round trip: %v", decoded)
+	}
+
+	event.Payload["colour"] = "red"
+	assertPayloadViolation(t, validator.Validate(context.Background(), event), "protobuf_schema")
+
+	missing := newPayloadFormatValidator(t, dir, fakeRegistry{schemaName: "orders", format: PayloadFormatProtobuf, protoMessage: "orders.Refund"})
+	event = &Event{Type: "OrderEvent", Payload: map[string]interface{}{"order_id": "o-1"}}
+	if err := missing.Validate(context.Background(), event); !errors.Is(err, ErrSchemaNotFound) {
+		t.Errorf("expected ErrSchemaNotFound for unknown message, got %v", err)
+	}
+}
+
+func TestJSONSchemaValidator_ChannelFormatMustMatchSchema(t *testing.T) {
+	validator := setupVersionedValidator(t, fakeRegistry{format: PayloadFormatAvro})
+
+	event := &Event{Type: "OrderStatusEvent", Payload: map[string]interface{}{"order_id": "1"}}
+	if err := validator.Validate(context.Background(), event); !errors.Is(err, ErrSchemaNotFound) {
+		t.Errorf("expected ErrSchemaNotFound for JSON schema on avro channel, got %v", err)
+	}
+}
+
+func TestLoadSchemaSet_RejectsMixedFormats(t *testing.T) {
+	dir := t.TempDir()
+	writeSchemaFile(t, dir, "orders.schema.json", `{"type": "object"}`)
+	writeSchemaFile(t, dir, "orders.v2.avsc", `{"type": "record", "name": "Order", "fields": []}`)
+
+	if _, err := LoadSchemaSet(dir); err == nil {
+		t.Error("expected schema with JSON and Avro versions to be rejected")
+	}
+}
+
+// === Test Helpers ===
+
+func newPayloadFormatValidator(t *testing.T, dir string, registry fakeRegistry) *JSONSchemaValidator {
+	t.Helper()
+	validator, err := NewJSONSchemaValidator(dir, registry)
+	if err != nil {
+		t.Fatalf("failed to create validator: %v", err)
+	}
+	return validator
+}
+
+// writeOrderProtoset writes a FileDescriptorSet for orders.OrderStatus{order_id, quantity}.
+func writeOrderProtoset(t *testing.T, path string) {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:   proto.String(name),
+			Number: proto.Int32(number),
+			Type:   typ.Enum(),
+			Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		}
+	}
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{{
+		Name:    proto.String("orders.proto"),
+		Package: proto.String("orders"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("OrderStatus"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("order_id", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				field("quantity", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+			},
+		}},
+	}}}
+	data, err := proto.Marshal(set)
+	assertNoError(t, err)
+	assertNoError(t, os.WriteFile(path, data, 0644))
+}
+
+func assertPayloadViolation(t *testing.T, err error, rule string) {
+	t.Helper()
+	var validationErr *EventValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Violations) != 1 {
+		t.Fatalf("expected one payload violation, got %v", err)
+	}
+	if v := validationErr.Violations[0]; v.Pointer != "/payload" || v.Rule != rule {
+		t.Errorf("expected %s violation on /payload, got %+v", rule, v)
+	}
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
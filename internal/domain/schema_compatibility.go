@@ -0,0 +1,315 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SchemaCompatibility — класс изменения схемы с точки зрения уже отправляемых документов.
+type SchemaCompatibility string
+
+const (
+	// CompatibilityFull — изменение не влияет на валидацию.
+	CompatibilityFull SchemaCompatibility = "full"
+	// CompatibilityBackward — новая схема принимает все, что принимала старая (ослабление ограничений).
+	CompatibilityBackward SchemaCompatibility = "backward"
+	// CompatibilityForward — старая схема принимает документы новой: добавлены только новые
+	// необязательные поля, которые текущие producers не отправляют.
+	CompatibilityForward SchemaCompatibility = "forward"
+	// CompatibilityBreaking — новая схема отклоняет документы, которые producers уже отправляют.
+	CompatibilityBreaking SchemaCompatibility = "breaking"
+)
+
+// SchemaChange — одно изменение между двумя версиями схемы.
+type SchemaChange struct {
+	Path          string              `json:"path"` // JSON pointer внутри схемы
+	Description   string              `json:"description"`
+	Compatibility SchemaCompatibility `json:"compatibility"`
+}
+
+type SchemaCompatibilityReport struct {
+	Compatibility SchemaCompatibility `json:"compatibility"`
+	Changes       []SchemaChange      `json:"changes"`
+}
+
+// Breaking сообщает, отклоняет ли новая схема документы, валидные для старой.
+func (r *SchemaCompatibilityReport) Breaking() bool {
+	return r.Compatibility == CompatibilityBreaking
+}
+
+// CheckSchemaCompatibility сравнивает две JSON Schema и классифицирует изменение.
+// Учитываются type, enum, const, required, properties, additionalProperties, items,
+// pattern, format и числовые/длинновые ограничения; описательные ключи игнорируются.
+// Если изменения разных направлений смешаны (backward и forward), результат — breaking.
+func CheckSchemaCompatibility(oldSchema, newSchema []byte) (*SchemaCompatibilityReport, error) {
+	var oldDoc, newDoc map[string]interface{}
+	if err := json.Unmarshal(oldSchema, &oldDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse old schema: %w", err)
+	}
+	if err := json.Unmarshal(newSchema, &newDoc); err != nil {
+		return nil, fmt.Errorf("failed to parse new schema: %w", err)
+	}
+
+	c := &schemaComparer{}
+	c.compare("", oldDoc, newDoc)
+
+	report := &SchemaCompatibilityReport{Compatibility: CompatibilityFull, Changes: c.changes}
+	if report.Changes == nil {
+		report.Changes = []SchemaChange{}
+	}
+	for _, change := range c.changes {
+		switch {
+		case change.Compatibility == CompatibilityBreaking:
+			report.Compatibility = CompatibilityBreaking
+		case report.Compatibility == CompatibilityFull:
+			report.Compatibility = change.Compatibility
+		case report.Compatibility != change.Compatibility:
+			report.Compatibility = CompatibilityBreaking
+		}
+	}
+	return report, nil
+}
+
+type schemaComparer struct {
+	changes []SchemaChange
+}
+
+func (c *schemaComparer) add(path string, compatibility SchemaCompatibility, format string, args ...interface{}) {
+	if path == "" {
+		path = "/"
+	}
+	c.changes = append(c.changes, SchemaChange{
+		Path:          path,
+		Description:   fmt.Sprintf(format, args...),
+		Compatibility: compatibility,
+	})
+}
+
+var (
+	lowerBoundKeywords = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+	upperBoundKeywords = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+)
+
+func (c *schemaComparer) compare(path string, oldDoc, newDoc map[string]interface{}) {
+	c.compareTypes(path, oldDoc["type"], newDoc["type"])
+	c.compareEnum(path, oldDoc["enum"], newDoc["enum"])
+	c.compareExact(path, "const", oldDoc, newDoc)
+	c.compareExact(path, "pattern", oldDoc, newDoc)
+	c.compareExact(path, "format", oldDoc, newDoc)
+	for _, kw := range lowerBoundKeywords {
+		c.compareBound(path, kw, oldDoc, newDoc, true)
+	}
+	for _, kw := range upperBoundKeywords {
+		c.compareBound(path, kw, oldDoc, newDoc, false)
+	}
+	c.compareRequired(path, oldDoc["required"], newDoc["required"])
+	c.compareProperties(path, oldDoc, newDoc)
+
+	oldItems, oldOk := oldDoc["items"].(map[string]interface{})
+	newItems, newOk := newDoc["items"].(map[string]interface{})
+	if oldOk && newOk {
+		c.compare(path+"/items", oldItems, newItems)
+	}
+}
+
+func (c *schemaComparer) compareTypes(path string, oldType, newType interface{}) {
+	oldSet, newSet := typeSet(oldType), typeSet(newType)
+	if reflect.DeepEqual(oldSet, newSet) {
+		return
+	}
+	switch {
+	case oldSet == nil:
+		c.add(path, CompatibilityBreaking, "type restricted to %s", joinKeys(newSet))
+	case newSet == nil:
+		c.add(path, CompatibilityBackward, "type restriction %s removed", joinKeys(oldSet))
+	case typesCover(newSet, oldSet):
+		c.add(path, CompatibilityBackward, "type widened from %s to %s", joinKeys(oldSet), joinKeys(newSet))
+	default:
+		c.add(path, CompatibilityBreaking, "type changed from %s to %s", joinKeys(oldSet), joinKeys(newSet))
+	}
+}
+
+func (c *schemaComparer) compareEnum(path string, oldEnum, newEnum interface{}) {
+	oldSet, newSet := valueSet(oldEnum), valueSet(newEnum)
+	switch {
+	case oldSet == nil && newSet == nil:
+		return
+	case oldSet == nil:
+		c.add(path, CompatibilityBreaking, "enum %s added", joinKeys(newSet))
+		return
+	case newSet == nil:
+		c.add(path, CompatibilityBackward, "enum %s removed", joinKeys(oldSet))
+		return
+	}
+	var removed, added []string
+	for v := range oldSet {
+		if !newSet[v] {
+			removed = append(removed, v)
+		}
+	}
+	for v := range newSet {
+		if !oldSet[v] {
+			added = append(added, v)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	if len(removed) > 0 {
+		c.add(path, CompatibilityBreaking, "enum narrowed: removed %s", strings.Join(removed, ", "))
+	}
+	if len(added) > 0 {
+		c.add(path, CompatibilityBackward, "enum widened: added %s", strings.Join(added, ", "))
+	}
+}
+
+// compareExact — для ключей, любое добавление или изменение которых сужает схему.
+func (c *schemaComparer) compareExact(path, keyword string, oldDoc, newDoc map[string]interface{}) {
+	oldVal, oldOk := oldDoc[keyword]
+	newVal, newOk := newDoc[keyword]
+	switch {
+	case !oldOk && !newOk:
+	case !oldOk:
+		c.add(path, CompatibilityBreaking, "%s %v added", keyword, newVal)
+	case !newOk:
+		c.add(path, CompatibilityBackward, "%s %v removed", keyword, oldVal)
+	case !reflect.DeepEqual(oldVal, newVal):
+		c.add(path, CompatibilityBreaking, "%s changed from %v to %v", keyword, oldVal, newVal)
+	}
+}
+
+func (c *schemaComparer) compareBound(path, keyword string, oldDoc, newDoc map[string]interface{}, lower bool) {
+	oldVal, oldOk := oldDoc[keyword].(float64)
+	newVal, newOk := newDoc[keyword].(float64)
+	switch {
+	case !oldOk && !newOk:
+	case !oldOk:
+		c.add(path, CompatibilityBreaking, "%s %v added", keyword, newVal)
+	case !newOk:
+		c.add(path, CompatibilityBackward, "%s %v removed", keyword, oldVal)
+	case oldVal == newVal:
+	case (newVal > oldVal) == lower:
+		c.add(path, CompatibilityBreaking, "%s tightened from %v to %v", keyword, oldVal, newVal)
+	default:
+		c.add(path, CompatibilityBackward, "%s relaxed from %v to %v", keyword, oldVal, newVal)
+	}
+}
+
+func (c *schemaComparer) compareRequired(path string, oldRequired, newRequired interface{}) {
+	oldSet, newSet := valueSet(oldRequired), valueSet(newRequired)
+	for _, name := range sortedKeys(newSet) {
+		if !oldSet[name] {
+			c.add(path+"/required", CompatibilityBreaking, "field %s is now required", name)
+		}
+	}
+	for _, name := range sortedKeys(oldSet) {
+		if !newSet[name] {
+			c.add(path+"/required", CompatibilityBackward, "field %s is no longer required", name)
+		}
+	}
+}
+
+func (c *schemaComparer) compareProperties(path string, oldDoc, newDoc map[string]interface{}) {
+	oldProps, _ := oldDoc["properties"].(map[string]interface{})
+	newProps, _ := newDoc["properties"].(map[string]interface{})
+	oldClosed := oldDoc["additionalProperties"] == false
+	newClosed := newDoc["additionalProperties"] == false
+
+	for _, name := range sortedKeys(newProps) {
+		propPath := path + "/properties/" + escapePointer(name)
+		newProp, _ := newProps[name].(map[string]interface{})
+		oldPropRaw, exists := oldProps[name]
+		if !exists {
+			if oldClosed {
+				c.add(propPath, CompatibilityBackward, "property %s added", name)
+			} else {
+				c.add(propPath, CompatibilityForward, "optional property %s added", name)
+			}
+			continue
+		}
+		oldProp, _ := oldPropRaw.(map[string]interface{})
+		if oldProp != nil && newProp != nil {
+			c.compare(propPath, oldProp, newProp)
+		}
+	}
+	for _, name := range sortedKeys(oldProps) {
+		if _, exists := newProps[name]; exists {
+			continue
+		}
+		propPath := path + "/properties/" + escapePointer(name)
+		if newClosed {
+			c.add(propPath, CompatibilityBreaking, "property %s removed from closed object", name)
+		} else {
+			c.add(propPath, CompatibilityBackward, "property %s removed", name)
+		}
+	}
+
+	switch {
+	case !oldClosed && newClosed:
+		c.add(path+"/additionalProperties", CompatibilityBreaking, "additional properties are no longer allowed")
+	case oldClosed && !newClosed:
+		c.add(path+"/additionalProperties", CompatibilityBackward, "additional properties are now allowed")
+	}
+}
+
+func typeSet(v interface{}) map[string]bool {
+	switch t := v.(type) {
+	case string:
+		return map[string]bool{t: true}
+	case []interface{}:
+		set := make(map[string]bool, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				set[s] = true
+			}
+		}
+		return set
+	}
+	return nil
+}
+
+// typesCover сообщает, принимает ли набор типов wide все значения набора narrow.
+func typesCover(wide, narrow map[string]bool) bool {
+	for t := range narrow {
+		if !wide[t] && !(t == "integer" && wide["number"]) {
+			return false
+		}
+	}
+	return true
+}
+
+func valueSet(v interface{}) map[string]bool {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			set[s] = true
+			continue
+		}
+		data, _ := json.Marshal(item)
+		set[string(data)] = true
+	}
+	return set
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinKeys(set map[string]bool) string {
+	return strings.Join(sortedKeys(set), "|")
+}
+
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"testing"
+)
+
+const baseOrderSchema = `{
+	"type": "object",
+	"properties": {
+		"order_id": {"type": "string", "pattern": "^[0-9]+$"},
+		"status":   {"type": "string", "enum": ["created", "packed", "shipped"]},
+		"message":  {"type": "string", "maxLength": 100}
+	},
+	"required": ["order_id", "status"]
+}`
+
+func TestCheckSchemaCompatibility(t *testing.T) {
+	cases := []struct {
+		name      string
+		newSchema string
+		expected  SchemaCompatibility
+	}{
+		{
+			name:      "identical with new description",
+			newSchema: `{"description": "orders", "type": "object", "properties": {"order_id": {"type": "string", "pattern": "^[0-9]+$"}, "status": {"type": "string", "enum": ["created", "packed", "shipped"]}, "message": {"type": "string", "maxLength": 100}}, "required": ["order_id", "status"]}`,
+			expected:  CompatibilityFull,
+		},
+		{
+			name:      "field newly required",
+			newSchema: `{"type": "object", "properties": {"order_id": {"type": "string", "pattern": "^[0-9]+$"}, "status": {"type": "string", "enum": ["created", "packed", "shipped"]}, "message": {"type": "string", "maxLength": 100}}, "required": ["order_id", "status", "message"]}`,
+			expected:  CompatibilityBreaking,
+		},
+		{
+			name:      "enum narrowed",
+			newSchema: `{"type": "object", "properties": {"order_id": {"type": "string", "pattern": "^[0-9]+$"}, "status": {"type": "string", "enum": ["created", "packed"]}, "message": {"type": "string", "maxLength": 100}}, "required": ["order_id", "status"]}`,
+			expected:  CompatibilityBreaking,
+		},
+		{
+			name:      "type changed",
+			newSchema: `{"type": "object", "properties": {"order_id": {"type": "integer"}, "status": {"type": "string", "enum": ["created", "packed", "shipped"]}, "message": {"type": "string", "maxLength": 100}}, "required": ["order_id", "status"]}`,
+			expected:  CompatibilityBreaking,
+		},
+		{
+			name:      "enum widened and limit relaxed",
+			newSchema: `{"type": "object", "properties": {"order_id": {"type": "string", "pattern": "^[0-9]+$"}, "status": {"type": "string", "enum": ["created", "packed", "shipped", "delivered"]}, "message": {"type": "string", "maxLength": 500}}, "required": ["order_id", "status"]}`,
+			expected:  CompatibilityBackward,
+		},
+		{
+			name:      "requirement dropped",
+			newSchema: `{"type": "object", "properties": {"order_id": {"type": "string", "pattern": "^[0-9]+$"}, "status": {"type": "string", "enum": ["created", "packed", "shipped"]}, "message": {"type": "string", "maxLength": 100}}, "required": ["order_id"]}`,
+			expected:  CompatibilityBackward,
+		},
+		{
+			name:      "optional property added",
+			newSchema: `{"type": "object", "properties": {"order_id": {"type": "string", "pattern": "^[0-9]+$"}, "status": {"type": "string", "enum": ["created", "packed", "shipped"]}, "message": {"type": "string", "maxLength": 100}, "carrier": {"type": "string"}}, "required": ["order_id", "status"]}`,
+			expected:  CompatibilityForward,
+		},
+		{
+			name:      "additional properties closed",
+			newSchema: `{"type": "object", "additionalProperties": false, "properties": {"order_id": {"type": "string", "pattern": "^[0-9]+$"}, "status": {"type": "string", "enum": ["created", "packed", "shipped"]}, "message": {"type": "string", "maxLength": 100}}, "required": ["order_id", "status"]}`,
+			expected:  CompatibilityBreaking,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := CheckSchemaCompatibility([]byte(baseOrderSchema), []byte(tc.newSchema))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report.Compatibility != tc.expected {
+				t.Errorf("expected %s, got %s (changes: %+v)", tc.expected, report.Compatibility, report.Changes)
+			}
+		})
+	}
+}
+
+func TestCheckSchemaCompatibility_ReportsChangePaths(t *testing.T) {
+	newSchema := `{"type": "object", "properties": {"order_id": {"type": "string", "pattern": "^[0-9]+$"}, "status": {"type": "string", "enum": ["created"]}, "message": {"type": "string", "maxLength": 100}}, "required": ["order_id", "status"]}`
+
+	report, err := CheckSchemaCompatibility([]byte(baseOrderSchema), []byte(newSchema))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Changes) != 1 || report.Changes[0].Path != "/properties/status" {
+		t.Fatalf("expected a single change at /properties/status, got %+v", report.Changes)
+	}
+}
@@ -0,0 +1,134 @@
+package infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+var (
+	ErrChannelNotFound = errors.New("channel not found")
+	ErrChannelExists   = errors.New("channel already exists")
+	ErrInvalidChannel  = errors.New("invalid channel")
+)
+
+// ChannelAdmin создает, изменяет и удаляет каналы: проверяет конфигурацию,
+// сохраняет channels.json и пишет каждое изменение в журнал аудита.
+type ChannelAdmin struct {
+	reloader   *ConfigReloader
+	knownTypes map[string]bool
+	audit      *ChannelAuditLog
+}
+
+// NewChannelAdmin принимает список типов каналов, для которых зарегистрирован publisher.
+func NewChannelAdmin(reloader *ConfigReloader, knownTypes []string, audit *ChannelAuditLog) *ChannelAdmin {
+	types := make(map[string]bool, len(knownTypes))
+	for _, t := range knownTypes {
+		types[t] = true
+	}
+	return &ChannelAdmin{reloader: reloader, knownTypes: types, audit: audit}
+}
+
+// Create добавляет новый канал.
+func (a *ChannelAdmin) Create(actor, name string, info EventChannelInfo) error {
+	return a.modify(actor, "create", name, func(current *EventChannelInfo) (*EventChannelInfo, error) {
+		if current != nil {
+			return nil, fmt.Errorf("%w: %s", ErrChannelExists, name)
+		}
+		return &info, nil
+	})
+}
+
+// Update заменяет конфигурацию существующего канала.
+func (a *ChannelAdmin) Update(actor, name string, info EventChannelInfo) error {
+	return a.modify(actor, "update", name, func(current *EventChannelInfo) (*EventChannelInfo, error) {
+		if current == nil {
+			return nil, fmt.Errorf("%w: %s", ErrChannelNotFound, name)
+		}
+		return &info, nil
+	})
+}
+
+// Delete удаляет канал.
+func (a *ChannelAdmin) Delete(actor, name string) error {
+	return a.modify(actor, "delete", name, func(current *EventChannelInfo) (*EventChannelInfo, error) {
+		if current == nil {
+			return nil, fmt.Errorf("%w: %s", ErrChannelNotFound, name)
+		}
+		return nil, nil
+	})
+}
+
+// AuditEntries возвращает журнал изменений каналов.
+func (a *ChannelAdmin) AuditEntries() ([]ChannelAuditEntry, error) {
+	return a.audit.Entries()
+}
+
+// modify применяет change к каналу name. change получает текущую конфигурацию
+// (nil — канала нет) и возвращает новую (nil — удалить).
+func (a *ChannelAdmin) modify(actor, action, name string, change func(current *EventChannelInfo) (*EventChannelInfo, error)) error {
+	if name == "" {
+		return fmt.Errorf("%w: empty channel name", ErrInvalidChannel)
+	}
+
+	var before, after *EventChannelInfo
+	err := a.reloader.ModifyChannels(func(channels map[string]EventChannelInfo) error {
+		if info, ok := channels[name]; ok {
+			before = &info
+		}
+		next, err := change(before)
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			delete(channels, name)
+			return nil
+		}
+		normalized, err := a.validate(*next)
+		if err != nil {
+			return err
+		}
+		after = &normalized
+		channels[name] = normalized
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	entry := ChannelAuditEntry{
+		Time:    time.Now().UTC(),
+		Actor:   actor,
+		Action:  action,
+		Channel: name,
+		Before:  before,
+		After:   after,
+	}
+	if err := a.audit.Append(entry); err != nil {
+		// Изменение уже применено; потеря записи аудита не должна его откатывать
+		log.Printf("failed to write channel audit entry: %v", err)
+	}
+	log.Printf("🛠 Channel %s: %s by %s", name, action, actor)
+	return nil
+}
+
+// validate проверяет канал и возвращает его нормализованную конфигурацию.
+func (a *ChannelAdmin) validate(info EventChannelInfo) (EventChannelInfo, error) {
+	if info.SchemaName == "" {
+		return info, fmt.Errorf("%w: schema is required", ErrInvalidChannel)
+	}
+	normalized, err := normalizeChannel(info)
+	if err != nil {
+		return info, fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+	}
+	if normalized.Endpoint == "" {
+		return info, fmt.Errorf("%w: endpoint is required", ErrInvalidChannel)
+	}
+	for _, d := range normalized.Targets() {
+		if !a.knownTypes[d.Type] {
+			return info, fmt.Errorf("%w: unknown publisher type %q", ErrInvalidChannel, d.Type)
+		}
+	}
+	return normalized, nil
+}
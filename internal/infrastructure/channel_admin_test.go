@@ -0,0 +1,104 @@
+package infrastructure
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestChannelAdmin_CreatePersistsAndAudits(t *testing.T) {
+	admin, reloader, dir := setupChannelAdmin(t)
+
+	info := EventChannelInfo{Type: "kafka", Endpoint: "orders-v2-topic", SchemaName: "order"}
+	assertNoError(t, admin.Create("alice", "OrderCreatedEvent", info))
+
+	if _, ok := reloader.Registry.GetChannel("OrderCreatedEvent"); !ok {
+		t.Fatal("expected channel to be applied to the registry")
+	}
+
+	// The file on disk must contain the new channel
+	reread, err := NewEventRegistryFromFile(filepath.Join(dir, "channels.json"))
+	assertNoError(t, err)
+	if got, ok := reread.GetChannel("OrderCreatedEvent"); !ok || got.Endpoint != "orders-v2-topic" {
+		t.Fatalf("expected channel to be persisted, got %+v", got)
+	}
+
+	entries, err := admin.AuditEntries()
+	assertNoError(t, err)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Actor != "alice" || entry.Action != "create" || entry.Before != nil || entry.After == nil {
+		t.Errorf("unexpected audit entry: %+v", entry)
+	}
+}
+
+func TestChannelAdmin_UpdateAndDelete(t *testing.T) {
+	admin, reloader, _ := setupChannelAdmin(t)
+
+	updated := EventChannelInfo{Type: "kafka", Endpoint: "orders-topic-v2", SchemaName: "order"}
+	assertNoError(t, admin.Update("bob", "OrderStatusEvent", updated))
+	if got, _ := reloader.Registry.GetChannel("OrderStatusEvent"); got.Endpoint != "orders-topic-v2" {
+		t.Errorf("expected updated endpoint, got %s", got.Endpoint)
+	}
+
+	assertNoError(t, admin.Delete("bob", "OrderStatusEvent"))
+	if _, ok := reloader.Registry.GetChannel("OrderStatusEvent"); ok {
+		t.Error("expected channel to be deleted")
+	}
+
+	entries, _ := admin.AuditEntries()
+	if len(entries) != 2 || entries[0].Before.Endpoint != "orders-topic" || entries[1].After != nil {
+		t.Errorf("expected update and delete audit entries with before/after, got %+v", entries)
+	}
+}
+
+func TestChannelAdmin_Validation(t *testing.T) {
+	admin, reloader, _ := setupChannelAdmin(t)
+
+	cases := []struct {
+		name     string
+		apply    func() error
+		expected error
+	}{
+		{"unknown schema", func() error {
+			return admin.Create("alice", "PaymentEvent", EventChannelInfo{Type: "kafka", Endpoint: "payments", SchemaName: "payment"})
+		}, ErrInvalidChannel},
+		{"unknown type", func() error {
+			return admin.Create("alice", "PaymentEvent", EventChannelInfo{Type: "carrier-pigeon", Endpoint: "payments", SchemaName: "order"})
+		}, ErrInvalidChannel},
+		{"duplicate", func() error {
+			return admin.Create("alice", "OrderStatusEvent", EventChannelInfo{Type: "kafka", Endpoint: "orders", SchemaName: "order"})
+		}, ErrChannelExists},
+		{"update missing", func() error {
+			return admin.Update("alice", "PaymentEvent", EventChannelInfo{Type: "kafka", Endpoint: "payments", SchemaName: "order"})
+		}, ErrChannelNotFound},
+		{"delete missing", func() error {
+			return admin.Delete("alice", "PaymentEvent")
+		}, ErrChannelNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.apply(); !errors.Is(err, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	if _, ok := reloader.Registry.GetChannel("PaymentEvent"); ok {
+		t.Error("rejected channel must not be applied")
+	}
+	entries, _ := admin.AuditEntries()
+	if len(entries) != 0 {
+		t.Errorf("rejected changes must not be audited, got %d entries", len(entries))
+	}
+}
+
+// === Test Helpers ===
+
+func setupChannelAdmin(t *testing.T) (*ChannelAdmin, *ConfigReloader, string) {
+	reloader, dir := setupConfigReloader(t)
+	audit := NewChannelAuditLog(filepath.Join(dir, "channels.audit.log"))
+	return NewChannelAdmin(reloader, []string{"kafka"}, audit), reloader, dir
+}
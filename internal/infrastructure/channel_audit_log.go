@@ -0,0 +1,82 @@
+package infrastructure
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ChannelAuditEntry — одно изменение канала через admin API.
+type ChannelAuditEntry struct {
+	Time    time.Time         `json:"time"`
+	Actor   string            `json:"actor"`
+	Action  string            `json:"action"` // create, update, delete
+	Channel string            `json:"channel"`
+	Before  *EventChannelInfo `json:"before,omitempty"`
+	After   *EventChannelInfo `json:"after,omitempty"`
+}
+
+// ChannelAuditLog дописывает записи аудита в файл в формате JSON Lines.
+type ChannelAuditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewChannelAuditLog(path string) *ChannelAuditLog {
+	return &ChannelAuditLog{path: path}
+}
+
+func (l *ChannelAuditLog) Append(entry ChannelAuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("cannot encode audit entry: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("cannot write audit log: %w", err)
+	}
+	return f.Sync()
+}
+
+// Entries возвращает все записи в порядке добавления.
+func (l *ChannelAuditLog) Entries() ([]ChannelAuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []ChannelAuditEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open audit log: %w", err)
+	}
+	defer f.Close()
+
+	entries := []ChannelAuditEntry{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry ChannelAuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("cannot decode audit log: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,194 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"maps"
+	"mime"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCloudEvent — сообщение не является корректным CloudEvent 1.0 или его data не JSON-объект.
+var ErrInvalidCloudEvent = errors.New("invalid CloudEvent")
+
+const (
+	CloudEventsContentType   = "application/cloudevents+json"
+	cloudEventsSpecVersion   = "1.0"
+	defaultCloudEventsSource = "event-system"
+)
+
+// CloudEventsMode — способ кодирования CloudEvents в сообщении Kafka (protocol binding).
+type CloudEventsMode string
+
+const (
+	CloudEventsBinary     CloudEventsMode = "binary"     // атрибуты в заголовках ce_*, value — data
+	CloudEventsStructured CloudEventsMode = "structured" // value — весь CloudEvent в JSON
+)
+
+// cloudEventsExtensionRe — допустимое имя атрибута CloudEvents.
+var cloudEventsExtensionRe = regexp.MustCompile(`^[a-z0-9]{1,20}$`)
+
+// Атрибуты-расширения, в которые отображаются поля domain.Event без аналога в спецификации.
+var cloudEventsMetadataExtensions = map[string]string{
+	"correlationid": "correlation-id",
+	"causationid":   "causation-id",
+	"tenant":        "tenant",
+	"actor":         "actor",
+}
+
+const cloudEventsSchemaVersionExtension = "schemaversion"
+
+// DecodeStructuredCloudEvent разбирает CloudEvent в structured-режиме (application/cloudevents+json).
+func DecodeStructuredCloudEvent(body []byte) (*domain.Event, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCloudEvent, err)
+	}
+	if _, ok := raw["data_base64"]; ok {
+		return nil, fmt.Errorf("%w: data_base64 is not supported, data must be a JSON object", ErrInvalidCloudEvent)
+	}
+	data := raw["data"]
+	delete(raw, "data")
+
+	attrs := make(map[string]string, len(raw))
+	for name, value := range raw {
+		var v interface{}
+		if err := json.Unmarshal(value, &v); err != nil {
+			return nil, fmt.Errorf("%w: attribute %s: %v", ErrInvalidCloudEvent, name, err)
+		}
+		switch v := v.(type) {
+		case string:
+			attrs[name] = v
+		case bool, float64:
+			attrs[name] = fmt.Sprint(v)
+		case nil:
+		default:
+			return nil, fmt.Errorf("%w: attribute %s must be a string, number or boolean", ErrInvalidCloudEvent, name)
+		}
+	}
+	return eventFromCloudEvent(attrs, data)
+}
+
+// DecodeBinaryCloudEvent разбирает CloudEvent в binary-режиме: attrs — атрибуты без
+// префикса ce-/ce_ (имена в нижнем регистре), contentType — тип data, data — тело.
+func DecodeBinaryCloudEvent(attrs map[string]string, contentType string, data []byte) (*domain.Event, error) {
+	attrs = maps.Clone(attrs)
+	if contentType != "" {
+		attrs["datacontenttype"] = contentType
+	}
+	return eventFromCloudEvent(attrs, data)
+}
+
+func eventFromCloudEvent(attrs map[string]string, data []byte) (*domain.Event, error) {
+	if attrs["specversion"] != cloudEventsSpecVersion {
+		return nil, fmt.Errorf("%w: specversion %q, want %s", ErrInvalidCloudEvent, attrs["specversion"], cloudEventsSpecVersion)
+	}
+	for _, name := range []string{"id", "source", "type"} {
+		if attrs[name] == "" {
+			return nil, fmt.Errorf("%w: attribute %s is required", ErrInvalidCloudEvent, name)
+		}
+	}
+	if ct := attrs["datacontenttype"]; ct != "" && !isJSONContentType(ct) {
+		return nil, fmt.Errorf("%w: datacontenttype %q is not JSON", ErrInvalidCloudEvent, ct)
+	}
+
+	event := &domain.Event{
+		ID:       attrs["id"],
+		Type:     attrs["type"],
+		Metadata: &domain.EventMetadata{Source: attrs["source"]},
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &event.Payload); err != nil {
+			return nil, fmt.Errorf("%w: data must be a JSON object: %v", ErrInvalidCloudEvent, err)
+		}
+	}
+	if t := attrs["time"]; t != "" {
+		ts, err := time.Parse(time.RFC3339Nano, t)
+		if err != nil {
+			return nil, fmt.Errorf("%w: time: %v", ErrInvalidCloudEvent, err)
+		}
+		event.Timestamp = ts
+	}
+	if v := attrs[cloudEventsSchemaVersionExtension]; v != "" {
+		version, err := strconv.Atoi(v)
+		if err != nil || version < 0 {
+			return nil, fmt.Errorf("%w: %s must be a non-negative integer", ErrInvalidCloudEvent, cloudEventsSchemaVersionExtension)
+		}
+		event.SchemaVersion = version
+	}
+
+	// Прочие расширения (кроме атрибутов спецификации) становятся метаданными
+	for name, value := range attrs {
+		switch name {
+		case "specversion", "id", "source", "type", "time", "datacontenttype", "dataschema", "subject", cloudEventsSchemaVersionExtension:
+			continue
+		}
+		if field, ok := cloudEventsMetadataExtensions[name]; ok {
+			event.Metadata.SetField(field, value)
+			continue
+		}
+		if event.Metadata.Headers == nil {
+			event.Metadata.Headers = make(map[string]string)
+		}
+		event.Metadata.Headers[name] = value
+	}
+	return event, nil
+}
+
+// cloudEventAttributes возвращает атрибуты CloudEvent для события (без data).
+// Свободные заголовки метаданных, чьи имена недопустимы в CloudEvents, пропускаются.
+func cloudEventAttributes(event *domain.Event) map[string]string {
+	attrs := map[string]string{
+		"specversion":     cloudEventsSpecVersion,
+		"id":              event.ID,
+		"type":            event.Type,
+		"source":          defaultCloudEventsSource,
+		"datacontenttype": "application/json",
+	}
+	if !event.Timestamp.IsZero() {
+		attrs["time"] = event.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	if event.SchemaVersion != 0 {
+		attrs[cloudEventsSchemaVersionExtension] = strconv.Itoa(event.SchemaVersion)
+	}
+	if m := event.Metadata; m != nil {
+		if m.Source != "" {
+			attrs["source"] = m.Source
+		}
+		fields := m.Fields()
+		for extension, field := range cloudEventsMetadataExtensions {
+			if value, ok := fields[field]; ok {
+				attrs[extension] = value
+			}
+		}
+		for name, value := range m.Headers {
+			if _, taken := attrs[name]; !taken && cloudEventsExtensionRe.MatchString(name) {
+				attrs[name] = value
+			}
+		}
+	}
+	return attrs
+}
+
+// EncodeStructuredCloudEvent кодирует событие как CloudEvent в structured-режиме.
+func EncodeStructuredCloudEvent(event *domain.Event) ([]byte, error) {
+	doc := make(map[string]interface{})
+	for name, value := range cloudEventAttributes(event) {
+		doc[name] = value
+	}
+	doc["data"] = event.Payload
+	return json.Marshal(doc)
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
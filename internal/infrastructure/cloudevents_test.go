@@ -0,0 +1,71 @@
+package infrastructure
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDecodeStructuredCloudEvent(t *testing.T) {
+	event, err := DecodeStructuredCloudEvent([]byte(`{
+		"specversion": "1.0", "id": "evt-1", "source": "/billing", "type": "OrderStatusEvent",
+		"time": "2024-05-01T12:00:00.5Z", "datacontenttype": "application/json",
+		"schemaversion": 3, "correlationid": "corr-1", "traceparent": "00-abc",
+		"data": {"order_id": "12345"}
+	}`))
+	assertNoError(t, err)
+
+	if event.ID != "evt-1" || event.Type != "OrderStatusEvent" || event.SchemaVersion != 3 {
+		t.Errorf("unexpected event: %+v", event)
+	}
+	if want := time.Date(2024, 5, 1, 12, 0, 0, 5e8, time.UTC); !event.Timestamp.Equal(want) {
+		t.Errorf("expected timestamp %v, got %v", want, event.Timestamp)
+	}
+	if event.Payload["order_id"] != "12345" {
+		t.Errorf("expected data as payload, got %v", event.Payload)
+	}
+	m := event.Metadata
+	if m.Source != "/billing" || m.CorrelationID != "corr-1" || m.Headers["traceparent"] != "00-abc" {
+		t.Errorf("unexpected metadata: %+v", m)
+	}
+}
+
+func TestDecodeCloudEvent_Invalid(t *testing.T) {
+	bodies := map[string]string{
+		"not json":           `{`,
+		"wrong specversion":  `{"specversion": "0.3", "id": "1", "source": "s", "type": "T"}`,
+		"missing source":     `{"specversion": "1.0", "id": "1", "type": "T"}`,
+		"xml data":           `{"specversion": "1.0", "id": "1", "source": "s", "type": "T", "datacontenttype": "application/xml"}`,
+		"base64 data":        `{"specversion": "1.0", "id": "1", "source": "s", "type": "T", "data_base64": "e30="}`,
+		"array data":         `{"specversion": "1.0", "id": "1", "source": "s", "type": "T", "data": [1]}`,
+		"bad time":           `{"specversion": "1.0", "id": "1", "source": "s", "type": "T", "time": "yesterday"}`,
+		"object attribute":   `{"specversion": "1.0", "id": "1", "source": "s", "type": "T", "subject": {}}`,
+		"bad schema version": `{"specversion": "1.0", "id": "1", "source": "s", "type": "T", "schemaversion": "v2"}`,
+	}
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DecodeStructuredCloudEvent([]byte(body)); !errors.Is(err, ErrInvalidCloudEvent) {
+				t.Errorf("expected ErrInvalidCloudEvent, got %v", err)
+			}
+		})
+	}
+
+	attrs := map[string]string{"specversion": "1.0", "id": "1", "source": "s", "type": "T"}
+	if _, err := DecodeBinaryCloudEvent(attrs, "text/plain", []byte("hi")); !errors.Is(err, ErrInvalidCloudEvent) {
+		t.Errorf("expected non-JSON binary data to be rejected, got %v", err)
+	}
+	if _, ok := attrs["datacontenttype"]; ok {
+		t.Error("DecodeBinaryCloudEvent must not modify the caller's attributes")
+	}
+}
+
+func TestCloudEventsMode_Validation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "channels.json")
+	config := `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders", "schema": "order", "cloudevents": "batched"}}`
+	assertNoError(t, os.WriteFile(path, []byte(config), 0644))
+	if _, err := NewEventRegistryFromFile(path); err == nil {
+		t.Error("expected unknown cloudevents mode to be rejected")
+	}
+}
@@ -0,0 +1,210 @@
+package infrastructure
+
+import (
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"log"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxReloadEvents — сколько последних перезагрузок хранится для admin API.
+const maxReloadEvents = 50
+
+// ErrInvalidConfig — channels.json или файлы схем содержат ошибку; перезагрузка
+// не поможет, пока конфигурацию не исправят.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// ChannelDiff — какие каналы добавлены, изменены или удалены перезагрузкой.
+type ChannelDiff struct {
+	Added   []string `json:"added"`
+	Changed []string `json:"changed"`
+	Removed []string `json:"removed"`
+}
+
+type ReloadReport struct {
+	Channels ChannelDiff          `json:"channels"`
+	Schemas  domain.SchemaDiff    `json:"schemas"`
+	Checks   []domain.SchemaCheck `json:"checks"`
+}
+
+// ReloadEvent — запись об одной попытке перезагрузки.
+type ReloadEvent struct {
+	Time    time.Time     `json:"time"`
+	Source  string        `json:"source"` // кто инициировал: admin, watcher
+	Success bool          `json:"success"`
+	Error   string        `json:"error,omitempty"`
+	Report  *ReloadReport `json:"report,omitempty"`
+}
+
+// ConfigReloader перезагружает каналы и схемы как одно целое: новая конфигурация
+// полностью проверяется до замены, и при любой ошибке остается рабочая.
+type ConfigReloader struct {
+	Registry  *EventRegistry
+	Validator *domain.JSONSchemaValidator
+	SchemaDir string
+
+	mu     sync.Mutex
+	events []ReloadEvent
+}
+
+func NewConfigReloader(registry *EventRegistry, validator *domain.JSONSchemaValidator, schemaDir string) *ConfigReloader {
+	return &ConfigReloader{
+		Registry:  registry,
+		Validator: validator,
+		SchemaDir: schemaDir,
+	}
+}
+
+// Reload читает channels.json и каталог схем, проверяет их и атомарно применяет.
+// Breaking изменения схем отклоняются с *domain.SchemaCompatibilityError, если не задан force.
+// Каждая попытка записывается в историю с указанием source.
+func (c *ConfigReloader) Reload(source string, force bool) (*ReloadReport, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report, err := c.reload(force)
+	event := ReloadEvent{Time: time.Now().UTC(), Source: source, Success: err == nil, Report: report}
+	if err != nil {
+		event.Error = err.Error()
+		log.Printf("config reload (%s) failed, keeping current config: %v", source, err)
+	}
+	c.events = append(c.events, event)
+	if len(c.events) > maxReloadEvents {
+		c.events = c.events[len(c.events)-maxReloadEvents:]
+	}
+	return report, err
+}
+
+// Events возвращает последние попытки перезагрузки, начиная с самой новой.
+func (c *ConfigReloader) Events() []ReloadEvent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make([]ReloadEvent, 0, len(c.events))
+	for i := len(c.events) - 1; i >= 0; i-- {
+		result = append(result, c.events[i])
+	}
+	return result
+}
+
+func (c *ConfigReloader) reload(force bool) (*ReloadReport, error) {
+	channels, err := c.Registry.Load()
+	if err != nil {
+		return nil, err
+	}
+	schemas, err := domain.LoadSchemaSet(c.SchemaDir)
+	if errors.Is(err, domain.ErrInvalidSchema) {
+		return nil, fmt.Errorf("%w: cannot load schemas: %w", ErrInvalidConfig, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot load schemas: %w", err)
+	}
+	if err := validateChannelSchemas(channels, schemas); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+
+	current := c.Validator.Schemas()
+	checks, err := current.CheckCompatibility(schemas)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	if checks == nil {
+		checks = []domain.SchemaCheck{}
+	}
+	report := &ReloadReport{
+		Channels: diffChannels(c.Registry.GetAllChannels(), channels),
+		Schemas:  current.Diff(schemas),
+		Checks:   checks,
+	}
+	if err := domain.RefuseBreaking(checks, force); err != nil {
+		return report, err
+	}
+
+	c.Validator.SwapSchemas(schemas, func() {
+		c.Registry.Apply(channels)
+	})
+	log.Printf("🔄 Config reloaded: channels +%d ~%d -%d, schemas +%d ~%d -%d",
+		len(report.Channels.Added), len(report.Channels.Changed), len(report.Channels.Removed),
+		len(report.Schemas.Added), len(report.Schemas.Changed), len(report.Schemas.Removed))
+	return report, nil
+}
+
+// ModifyChannels применяет modify к копии текущих каналов, проверяет результат
+// против загруженных схем, сохраняет его в файл и применяет. Выполняется под той же
+// блокировкой, что и Reload, поэтому не пересекается с перезагрузками.
+func (c *ConfigReloader) ModifyChannels(modify func(channels map[string]EventChannelInfo) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	channels := c.Registry.GetAllChannels()
+	if err := modify(channels); err != nil {
+		return err
+	}
+	if err := validateChannelSchemas(channels, c.Validator.Schemas()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+	}
+	if _, err := kafkaTopicSettings(channels); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidChannel, err)
+	}
+	if err := c.Registry.Save(channels); err != nil {
+		return err
+	}
+	c.Registry.Apply(channels)
+	return nil
+}
+
+// validateChannelSchemas проверяет, что каждый канал ссылается на загруженную схему и ее версии.
+func validateChannelSchemas(channels map[string]EventChannelInfo, schemas *domain.SchemaSet) error {
+	for _, name := range sortedChannelNames(channels) {
+		info := channels[name]
+		versions := schemas.Versions(info.SchemaName)
+		if len(versions) == 0 {
+			return fmt.Errorf("channel %q: no schema %q", name, info.SchemaName)
+		}
+		if format, _ := schemas.Format(info.SchemaName); format != info.PayloadFormat() {
+			return fmt.Errorf("channel %q: schema %q is %s, channel format is %s", name, info.SchemaName, format, info.PayloadFormat())
+		}
+		required := append([]int(nil), info.SchemaVersions...)
+		if info.DefaultSchemaVersion != 0 {
+			required = append(required, info.DefaultSchemaVersion)
+		}
+		for _, v := range required {
+			if !slices.Contains(versions, v) {
+				return fmt.Errorf("channel %q: schema %q has no version %d", name, info.SchemaName, v)
+			}
+		}
+	}
+	return nil
+}
+
+func diffChannels(current, next map[string]EventChannelInfo) ChannelDiff {
+	diff := ChannelDiff{Added: []string{}, Changed: []string{}, Removed: []string{}}
+	for _, name := range sortedChannelNames(next) {
+		old, ok := current[name]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, name)
+		case !reflect.DeepEqual(old, next[name]):
+			diff.Changed = append(diff.Changed, name)
+		}
+	}
+	for _, name := range sortedChannelNames(current) {
+		if _, ok := next[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	return diff
+}
+
+func sortedChannelNames(channels map[string]EventChannelInfo) []string {
+	names := make([]string, 0, len(channels))
+	for name := range channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
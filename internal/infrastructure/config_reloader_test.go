@@ -0,0 +1,123 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"event-system/internal/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const reloaderOrderSchema = `{"type": "object", "properties": {"order_id": {"type": "string"}}, "required": ["order_id"]}`
+
+func TestConfigReloader_AddsChannelWithNewSchema(t *testing.T) {
+	reloader, dir := setupConfigReloader(t)
+
+	writeConfigFile(t, dir, "schema/payment.schema.json", `{"type": "object", "required": ["amount"]}`)
+	writeConfigFile(t, dir, "channels.json", `{
+		"OrderStatusEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order"},
+		"PaymentEvent": {"type": "kafka", "endpoint": "payments-topic", "schema": "payment"}}`)
+
+	report, err := reloader.Reload("test", false)
+	assertNoError(t, err)
+
+	if len(report.Channels.Added) != 1 || report.Channels.Added[0] != "PaymentEvent" {
+		t.Errorf("expected PaymentEvent to be added, got %+v", report.Channels)
+	}
+	if len(report.Schemas.Added) != 1 || report.Schemas.Added[0] != "payment v1" {
+		t.Errorf("expected payment v1 to be added, got %+v", report.Schemas)
+	}
+
+	event := &domain.Event{ID: "evt-1", Type: "PaymentEvent", Timestamp: time.Now(), Payload: map[string]interface{}{"amount": 10}}
+	assertNoError(t, reloader.Validator.Validate(context.Background(), event))
+}
+
+func TestConfigReloader_KeepsWorkingConfigOnBrokenSchema(t *testing.T) {
+	reloader, dir := setupConfigReloader(t)
+
+	writeConfigFile(t, dir, "schema/order.schema.json", `{"type": "object", "required": `)
+
+	if _, err := reloader.Reload("test", false); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig for a broken schema, got %v", err)
+	}
+	assertNoError(t, reloader.Validator.Validate(context.Background(), createReloaderOrderEvent()))
+}
+
+func TestConfigReloader_RejectsChannelWithMissingSchema(t *testing.T) {
+	reloader, dir := setupConfigReloader(t)
+
+	writeConfigFile(t, dir, "channels.json", `{
+		"OrderStatusEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order"},
+		"PaymentEvent": {"type": "kafka", "endpoint": "payments-topic", "schema": "payment"}}`)
+
+	if _, err := reloader.Reload("test", false); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig for a channel without schema, got %v", err)
+	}
+	if _, ok := reloader.Registry.GetChannel("PaymentEvent"); ok {
+		t.Error("channel with missing schema must not be applied")
+	}
+}
+
+func TestConfigReloader_MissingFilesAreNotInvalidConfig(t *testing.T) {
+	reloader, dir := setupConfigReloader(t)
+	assertNoError(t, os.RemoveAll(filepath.Join(dir, "schema")))
+
+	_, err := reloader.Reload("test", false)
+	if err == nil || errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected an I/O error, got %v", err)
+	}
+}
+
+func TestConfigReloader_RefusesBreakingSchemaUnlessForced(t *testing.T) {
+	reloader, dir := setupConfigReloader(t)
+
+	writeConfigFile(t, dir, "schema/order.schema.json",
+		`{"type": "object", "properties": {"order_id": {"type": "string"}}, "required": ["order_id", "status"]}`)
+
+	report, err := reloader.Reload("test", false)
+	var compatErr *domain.SchemaCompatibilityError
+	if !errors.As(err, &compatErr) {
+		t.Fatalf("expected SchemaCompatibilityError, got %v", err)
+	}
+	if len(report.Schemas.Changed) != 1 {
+		t.Errorf("expected the refused report to list the changed schema, got %+v", report.Schemas)
+	}
+	assertNoError(t, reloader.Validator.Validate(context.Background(), createReloaderOrderEvent()))
+
+	_, err = reloader.Reload("test", true)
+	assertNoError(t, err)
+	if err := reloader.Validator.Validate(context.Background(), createReloaderOrderEvent()); err == nil {
+		t.Error("expected forced schema to be applied")
+	}
+}
+
+// === Test Helpers ===
+
+func setupConfigReloader(t *testing.T) (*ConfigReloader, string) {
+	dir := t.TempDir()
+	writeConfigFile(t, dir, "schema/order.schema.json", reloaderOrderSchema)
+	writeConfigFile(t, dir, "channels.json", `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order"}}`)
+
+	registry, err := NewEventRegistryFromFile(filepath.Join(dir, "channels.json"))
+	assertNoError(t, err)
+	validator, err := domain.NewJSONSchemaValidator(filepath.Join(dir, "schema"), registry)
+	assertNoError(t, err)
+
+	return NewConfigReloader(registry, validator, filepath.Join(dir, "schema")), dir
+}
+
+func writeConfigFile(t *testing.T, dir, name, content string) {
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+func createReloaderOrderEvent() *domain.Event {
+	return &domain.Event{ID: "evt-1", Type: "OrderStatusEvent", Timestamp: time.Now(), Payload: map[string]interface{}{"order_id": "1"}}
+}
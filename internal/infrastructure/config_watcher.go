@@ -0,0 +1,131 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type ConfigWatcherConfig struct {
+	PollInterval time.Duration // как часто проверять файлы
+	Debounce     time.Duration // сколько файлы должны оставаться неизменными перед перезагрузкой
+}
+
+// ConfigWatcher следит за channels.json и каталогом схем и перезагружает конфигурацию
+// через ConfigReloader. Изменения определяются опросом (размер и mtime файлов), поэтому
+// серия записей подряд приводит к одной перезагрузке после паузы Debounce.
+// При ошибке ConfigReloader оставляет последнюю рабочую конфигурацию; повторная попытка
+// будет после следующего изменения файлов.
+type ConfigWatcher struct {
+	reloader *ConfigReloader
+	cfg      ConfigWatcherConfig
+
+	applied   map[string]string // снимок, для которого последний раз выполнялась перезагрузка
+	pending   map[string]string // последний увиденный, еще не примененный снимок
+	changedAt time.Time
+}
+
+func NewConfigWatcher(reloader *ConfigReloader, cfg ConfigWatcherConfig) (*ConfigWatcher, error) {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.Debounce <= 0 {
+		cfg.Debounce = 2 * time.Second
+	}
+	w := &ConfigWatcher{reloader: reloader, cfg: cfg}
+	snapshot, err := w.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	w.applied = snapshot
+	return w, nil
+}
+
+// Run опрашивает файлы до отмены контекста.
+func (w *ConfigWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	log.Printf("👀 Watching %s and %s for changes", w.reloader.Registry.FilePath(), w.reloader.SchemaDir)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.poll(now)
+		}
+	}
+}
+
+// poll выполняет одну проверку и при необходимости перезагружает конфигурацию.
+func (w *ConfigWatcher) poll(now time.Time) {
+	current, err := w.snapshot()
+	if err != nil {
+		// Файл может отсутствовать посреди записи редактором — проверим на следующем тике
+		log.Printf("config watcher: %v", err)
+		return
+	}
+	if sameSnapshot(current, w.applied) {
+		w.pending = nil
+		return
+	}
+	if !sameSnapshot(current, w.pending) {
+		w.pending = current
+		w.changedAt = now
+		return
+	}
+	if now.Sub(w.changedAt) < w.cfg.Debounce {
+		return
+	}
+
+	w.applied = current
+	w.pending = nil
+	w.reloader.Reload("watcher", false)
+}
+
+// snapshot возвращает отпечаток (размер и mtime) файла каналов и файлов схем.
+func (w *ConfigWatcher) snapshot() (map[string]string, error) {
+	result := make(map[string]string)
+
+	registryPath := w.reloader.Registry.FilePath()
+	info, err := os.Stat(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot stat %s: %w", registryPath, err)
+	}
+	result[registryPath] = fingerprint(info)
+
+	entries, err := os.ReadDir(w.reloader.SchemaDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read schema dir %s: %w", w.reloader.SchemaDir, err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("cannot stat %s: %w", entry.Name(), err)
+		}
+		result[filepath.Join(w.reloader.SchemaDir, entry.Name())] = fingerprint(info)
+	}
+	return result, nil
+}
+
+func fingerprint(info os.FileInfo) string {
+	return fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+}
+
+func sameSnapshot(a, b map[string]string) bool {
+	if a == nil || b == nil || len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigWatcher_DebouncesAndReloads(t *testing.T) {
+	reloader, dir := setupConfigReloader(t)
+	watcher, err := NewConfigWatcher(reloader, ConfigWatcherConfig{Debounce: time.Second})
+	assertNoError(t, err)
+
+	now := time.Now()
+	writeConfigFile(t, dir, "schema/payment.schema.json", `{"type": "object"}`)
+	writeConfigFile(t, dir, "channels.json", `{
+		"OrderStatusEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order"},
+		"PaymentEvent": {"type": "kafka", "endpoint": "payments-topic", "schema": "payment"}}`)
+
+	// First sighting of the change only starts the debounce window
+	watcher.poll(now)
+	watcher.poll(now.Add(500 * time.Millisecond))
+	if len(reloader.Events()) != 0 {
+		t.Fatal("expected no reload before the debounce window elapses")
+	}
+
+	watcher.poll(now.Add(1500 * time.Millisecond))
+	events := reloader.Events()
+	if len(events) != 1 || !events[0].Success || events[0].Source != "watcher" {
+		t.Fatalf("expected one successful watcher reload, got %+v", events)
+	}
+	if _, ok := reloader.Registry.GetChannel("PaymentEvent"); !ok {
+		t.Error("expected PaymentEvent to be loaded")
+	}
+
+	// Nothing changed since the reload
+	watcher.poll(now.Add(5 * time.Second))
+	if len(reloader.Events()) != 1 {
+		t.Error("expected no further reloads without changes")
+	}
+}
+
+func TestConfigWatcher_KeepsLastGoodConfigOnError(t *testing.T) {
+	reloader, dir := setupConfigReloader(t)
+	watcher, err := NewConfigWatcher(reloader, ConfigWatcherConfig{Debounce: time.Millisecond})
+	assertNoError(t, err)
+
+	writeConfigFile(t, dir, "channels.json", `{"OrderStatusEvent": {`)
+
+	now := time.Now()
+	watcher.poll(now)
+	watcher.poll(now.Add(time.Second))
+
+	events := reloader.Events()
+	if len(events) != 1 || events[0].Success || events[0].Error == "" {
+		t.Fatalf("expected one failed reload event, got %+v", events)
+	}
+	if _, ok := reloader.Registry.GetChannel("OrderStatusEvent"); !ok {
+		t.Error("expected the last good channel config to be kept")
+	}
+
+	// The broken file is not retried until it changes again
+	watcher.poll(now.Add(2 * time.Second))
+	if len(reloader.Events()) != 1 {
+		t.Error("expected no retry for an unchanged broken config")
+	}
+}
@@ -0,0 +1,204 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrDeadLetterNotFound возвращается, если dead letter с таким ID нет.
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+const deadLetterSchema = `
+CREATE TABLE IF NOT EXISTS dead_letters (
+	id          TEXT    NOT NULL PRIMARY KEY,
+	event_id    TEXT    NOT NULL,
+	event_type  TEXT    NOT NULL,
+	destination TEXT    NOT NULL,
+	stage       TEXT    NOT NULL,
+	reason      TEXT    NOT NULL,
+	attempts    INTEGER NOT NULL,
+	event       TEXT    NOT NULL,
+	created_at  INTEGER NOT NULL,
+	updated_at  INTEGER NOT NULL,
+	replayed_at INTEGER
+);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_event ON dead_letters (event_id, replayed_at);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_destination ON dead_letters (destination, created_at);
+`
+
+// SQLiteDeadLetterStore хранит отклоненные события в очередях, заданных
+// полем dead_letter канала в channels.json.
+type SQLiteDeadLetterStore struct {
+	db       *sql.DB
+	registry *EventRegistry
+	now      func() time.Time
+}
+
+func NewSQLiteDeadLetterStore(dsn string, registry *EventRegistry) (*SQLiteDeadLetterStore, error) {
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", dsn, err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(deadLetterSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create dead letter schema: %w", err)
+	}
+
+	return &SQLiteDeadLetterStore{
+		db:       db,
+		registry: registry,
+		now:      time.Now,
+	}, nil
+}
+
+// Add сохраняет событие в dead letter очередь его канала. Если для события уже есть
+// неотыгранный dead letter, обновляется причина и увеличивается счетчик попыток.
+// Для каналов без dead_letter событие не сохраняется.
+func (s *SQLiteDeadLetterStore) Add(ctx context.Context, event *domain.Event, stage domain.DeadLetterStage, cause error) error {
+	info, ok := s.registry.GetChannel(event.Type)
+	if !ok || info.DeadLetter == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(domain.NewEventJSON(event))
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter event: %w", err)
+	}
+	now := s.now().UTC().UnixNano()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin dead letter transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id string
+	err = tx.QueryRowContext(ctx,
+		`SELECT id FROM dead_letters WHERE event_id = ? AND event_id != '' AND replayed_at IS NULL`,
+		event.ID,
+	).Scan(&id)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		id = uuid.New().String()
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO dead_letters (id, event_id, event_type, destination, stage, reason, attempts, event, created_at, updated_at)
+			 VALUES (?, ?, ?, ?, ?, ?, 1, ?, ?, ?)`,
+			id, event.ID, event.Type, info.DeadLetter, stage, cause.Error(), string(data), now, now,
+		)
+	case err == nil:
+		_, err = tx.ExecContext(ctx,
+			`UPDATE dead_letters SET destination = ?, stage = ?, reason = ?, attempts = attempts + 1, event = ?, updated_at = ?
+			 WHERE id = ?`,
+			info.DeadLetter, stage, cause.Error(), string(data), now, id,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store dead letter for event %s: %w", event.ID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit dead letter: %w", err)
+	}
+
+	log.Printf("☠️ Event %s (%s) moved to dead letter queue %s at %s stage", event.ID, event.Type, info.DeadLetter, stage)
+	return nil
+}
+
+// List возвращает dead letters очереди destination (или всех очередей, если она пуста).
+// Отыгранные записи включаются только при includeReplayed.
+func (s *SQLiteDeadLetterStore) List(destination string, includeReplayed bool) ([]*domain.DeadLetter, error) {
+	rows, err := s.db.Query(
+		`SELECT id, destination, stage, reason, attempts, event, created_at, updated_at, replayed_at
+		 FROM dead_letters
+		 WHERE (? = '' OR destination = ?) AND (? OR replayed_at IS NULL)
+		 ORDER BY created_at, id`,
+		destination, destination, includeReplayed,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*domain.DeadLetter
+	for rows.Next() {
+		letter, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, letter)
+	}
+	return result, rows.Err()
+}
+
+// Get возвращает dead letter по ID.
+func (s *SQLiteDeadLetterStore) Get(id string) (*domain.DeadLetter, error) {
+	row := s.db.QueryRow(
+		`SELECT id, destination, stage, reason, attempts, event, created_at, updated_at, replayed_at
+		 FROM dead_letters WHERE id = ?`,
+		id,
+	)
+	letter, err := scanDeadLetter(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("dead letter %s: %w", id, ErrDeadLetterNotFound)
+	}
+	return letter, err
+}
+
+// MarkReplayed помечает dead letter как успешно отыгранный.
+func (s *SQLiteDeadLetterStore) MarkReplayed(id string) error {
+	res, err := s.db.Exec(`UPDATE dead_letters SET replayed_at = ? WHERE id = ?`, s.now().UTC().UnixNano(), id)
+	if err != nil {
+		return fmt.Errorf("failed to mark dead letter %s replayed: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("dead letter %s: %w", id, ErrDeadLetterNotFound)
+	}
+	return nil
+}
+
+// Close закрывает соединение с базой.
+func (s *SQLiteDeadLetterStore) Close() error {
+	return s.db.Close()
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanDeadLetter(row rowScanner) (*domain.DeadLetter, error) {
+	var (
+		letter     domain.DeadLetter
+		stage      string
+		eventData  string
+		createdAt  int64
+		updatedAt  int64
+		replayedAt sql.NullInt64
+	)
+	err := row.Scan(&letter.ID, &letter.Destination, &stage, &letter.Reason, &letter.Attempts,
+		&eventData, &createdAt, &updatedAt, &replayedAt)
+	if err != nil {
+		return nil, err
+	}
+	var event domain.EventJSON
+	if err := json.Unmarshal([]byte(eventData), &event); err != nil {
+		return nil, fmt.Errorf("failed to decode dead letter event %s: %w", letter.ID, err)
+	}
+	letter.Event = event.Event()
+	letter.Stage = domain.DeadLetterStage(stage)
+	letter.CreatedAt = time.Unix(0, createdAt).UTC()
+	letter.UpdatedAt = time.Unix(0, updatedAt).UTC()
+	if replayedAt.Valid {
+		t := time.Unix(0, replayedAt.Int64).UTC()
+		letter.ReplayedAt = &t
+	}
+	return &letter, nil
+}
@@ -0,0 +1,98 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"event-system/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestSQLiteDeadLetterStore_AddAndGet(t *testing.T) {
+	store := setupDeadLetterStore(t)
+
+	event := createOrderEvent("evt-1", time.Now().UTC())
+	assertNoError(t, store.Add(context.Background(), event, domain.DeadLetterStageValidation, errors.New("status: must be one of enum")))
+
+	letters, err := store.List("orders-dlq", false)
+	assertNoError(t, err)
+	if len(letters) != 1 {
+		t.Fatalf("expected 1 dead letter, got %d", len(letters))
+	}
+
+	letter, err := store.Get(letters[0].ID)
+	assertNoError(t, err)
+	if letter.Stage != domain.DeadLetterStageValidation || letter.Attempts != 1 {
+		t.Errorf("expected validation stage with 1 attempt, got %s/%d", letter.Stage, letter.Attempts)
+	}
+	if letter.Reason != "status: must be one of enum" {
+		t.Errorf("unexpected reason: %s", letter.Reason)
+	}
+	if letter.Event.ID != "evt-1" || letter.Event.Payload["order_id"] != "12345" {
+		t.Errorf("expected original event to be preserved, got %+v", letter.Event)
+	}
+}
+
+func TestSQLiteDeadLetterStore_RepeatedFailureIncrementsAttempts(t *testing.T) {
+	store := setupDeadLetterStore(t)
+
+	event := createOrderEvent("evt-1", time.Now().UTC())
+	assertNoError(t, store.Add(context.Background(), event, domain.DeadLetterStageValidation, errors.New("invalid")))
+	assertNoError(t, store.Add(context.Background(), event, domain.DeadLetterStagePublish, errors.New("broker down")))
+
+	letters, err := store.List("", false)
+	assertNoError(t, err)
+	if len(letters) != 1 {
+		t.Fatalf("expected failures to be merged into 1 dead letter, got %d", len(letters))
+	}
+	if letters[0].Attempts != 2 || letters[0].Stage != domain.DeadLetterStagePublish {
+		t.Errorf("expected 2 attempts at publish stage, got %d at %s", letters[0].Attempts, letters[0].Stage)
+	}
+}
+
+func TestSQLiteDeadLetterStore_MarkReplayed(t *testing.T) {
+	store := setupDeadLetterStore(t)
+	assertNoError(t, store.Add(context.Background(), createOrderEvent("evt-1", time.Now().UTC()), domain.DeadLetterStagePublish, errors.New("broker down")))
+
+	letters, _ := store.List("", false)
+	assertNoError(t, store.MarkReplayed(letters[0].ID))
+
+	pending, _ := store.List("", false)
+	if len(pending) != 0 {
+		t.Errorf("expected replayed dead letter to be hidden, got %d", len(pending))
+	}
+	all, _ := store.List("", true)
+	if len(all) != 1 || all[0].ReplayedAt == nil {
+		t.Errorf("expected replayed dead letter with replayed_at, got %+v", all)
+	}
+
+	if err := store.MarkReplayed("missing"); !errors.Is(err, ErrDeadLetterNotFound) {
+		t.Errorf("expected ErrDeadLetterNotFound, got %v", err)
+	}
+}
+
+func TestSQLiteDeadLetterStore_SkipsChannelsWithoutDestination(t *testing.T) {
+	store, err := NewSQLiteDeadLetterStore(":memory:", createTestRegistry(t))
+	assertNoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	assertNoError(t, store.Add(context.Background(), createOrderEvent("evt-1", time.Now().UTC()), domain.DeadLetterStageValidation, errors.New("invalid")))
+
+	letters, _ := store.List("", true)
+	if len(letters) != 0 {
+		t.Errorf("expected no dead letters without a configured destination, got %d", len(letters))
+	}
+}
+
+// === Test Helpers ===
+
+func setupDeadLetterStore(t *testing.T) *SQLiteDeadLetterStore {
+	registry := createTestRegistryFromConfig(t, `{"OrderStatusEvent": {
+		"type": "kafka", "endpoint": "orders-topic", "schema": "order_status_notification", "dead_letter": "orders-dlq"}}`)
+	store, err := NewSQLiteDeadLetterStore(":memory:", registry)
+	if err != nil {
+		t.Fatalf("failed to create dead letter store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
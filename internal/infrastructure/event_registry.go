@@ -2,21 +2,127 @@ package infrastructure
 
 import (
 	"encoding/json"
+	"errors"
+	"event-system/internal/domain"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 )
 
 type EventChannelInfo struct {
-	Endpoint   string `json:"endpoint"`
-	SchemaName string `json:"schema"`
-	Type       string `json:"type"`
+	Endpoint             string               `json:"endpoint"`
+	SchemaName           string               `json:"schema"`
+	SchemaVersions       []int                `json:"schema_versions,omitempty"`        // принимаемые версии схемы; пусто — любые
+	DefaultSchemaVersion int                  `json:"default_schema_version,omitempty"` // версия для событий без schema_version
+	Type                 string               `json:"type"`
+	DeadLetter           string               `json:"dead_letter,omitempty"`     // очередь для отклоненных событий; пусто — не сохранять
+	Destinations         []ChannelDestination `json:"destinations,omitempty"`    // fan-out; пусто — только endpoint/type
+	DeliveryPolicy       DeliveryPolicy       `json:"delivery_policy,omitempty"` // что считать успешной доставкой; по умолчанию all
+	Webhook              *WebhookConfig       `json:"webhook,omitempty"`         // настройки webhook-destinations без собственного webhook
+	PartitionKey         string               `json:"partition_key,omitempty"`   // JSON pointer в payload, например /order_id; ключ сообщения Kafka
+	Kafka                *KafkaWriterConfig   `json:"kafka,omitempty"`           // настройки producer'а для kafka-destinations
+	TimestampWindow      *TimestampWindow     `json:"timestamp_window,omitempty"`
+	CloudEvents          CloudEventsMode      `json:"cloudevents,omitempty"`   // binary или structured: kafka-сообщения в формате CloudEvents; пусто — собственный формат
+	Format               domain.PayloadFormat `json:"format,omitempty"`        // json (по умолчанию), avro или protobuf: тип схемы и кодирование kafka-сообщений
+	ProtoMessage         string               `json:"proto_message,omitempty"` // полное имя сообщения Protobuf, например orders.OrderStatus
+}
+
+// PayloadFormat возвращает формат payload канала; пустое поле — json.
+func (info EventChannelInfo) PayloadFormat() domain.PayloadFormat {
+	if info.Format == "" {
+		return domain.PayloadFormatJSON
+	}
+	return info.Format
+}
+
+// TimestampWindow ограничивает timestamp принимаемых событий относительно времени сервера.
+// 0 — без ограничения.
+type TimestampWindow struct {
+	MaxPastMs   int64 `json:"max_past_ms,omitempty"`   // насколько событие может быть старше
+	MaxFutureMs int64 `json:"max_future_ms,omitempty"` // насколько может опережать часы сервера
+}
+
+// ChannelDestination — одна точка доставки канала.
+type ChannelDestination struct {
+	Type     string                   `json:"type"`
+	Endpoint string                   `json:"endpoint"`
+	Filter   map[string][]interface{} `json:"filter,omitempty"`  // поле payload верхнего уровня -> допустимые значения
+	Primary  bool                     `json:"primary,omitempty"` // основной endpoint канала (ResolveChannel)
+	Webhook  *WebhookConfig           `json:"webhook,omitempty"` // только для type webhook; nil — webhook канала
+}
+
+// DeliveryPolicy определяет, когда публикация в несколько destinations считается успешной.
+type DeliveryPolicy string
+
+const (
+	DeliveryPolicyAll     DeliveryPolicy = "all"     // все подходящие destinations
+	DeliveryPolicyAny     DeliveryPolicy = "any"     // хотя бы одна
+	DeliveryPolicyPrimary DeliveryPolicy = "primary" // только primary, остальные — best effort
+)
+
+// Targets возвращает destinations канала. Канал без destinations доставляется
+// в единственный endpoint своего type.
+func (info EventChannelInfo) Targets() []ChannelDestination {
+	if len(info.Destinations) == 0 {
+		return []ChannelDestination{{Type: info.Type, Endpoint: info.Endpoint, Primary: true, Webhook: info.Webhook}}
+	}
+	return info.Destinations
+}
+
+// webhookConfig возвращает настройки webhook-destination с этим URL: ее собственный
+// webhook, иначе webhook канала.
+func (info EventChannelInfo) webhookConfig(endpoint string) *WebhookConfig {
+	for _, d := range info.Targets() {
+		if d.Type == "webhook" && d.Endpoint == endpoint && d.Webhook != nil {
+			return d.Webhook
+		}
+	}
+	return info.Webhook
+}
+
+// Policy возвращает политику доставки с учетом значения по умолчанию.
+func (info EventChannelInfo) Policy() DeliveryPolicy {
+	if info.DeliveryPolicy == "" {
+		return DeliveryPolicyAll
+	}
+	return info.DeliveryPolicy
+}
+
+// Matches проверяет payload по фильтру: каждое поле фильтра должно быть в payload
+// и равняться одному из допустимых значений.
+func (d ChannelDestination) Matches(payload map[string]interface{}) bool {
+	for field, allowed := range d.Filter {
+		value, ok := payload[field]
+		if !ok {
+			return false
+		}
+		got, err := json.Marshal(value)
+		if err != nil {
+			return false
+		}
+		matched := false
+		for _, candidate := range allowed {
+			want, err := json.Marshal(candidate)
+			if err == nil && string(got) == string(want) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
 }
 
 type EventRegistry struct {
-	channels map[string]EventChannelInfo
-	filePath string
-	mu       sync.RWMutex
+	channels  map[string]EventChannelInfo
+	filePath  string
+	mu        sync.RWMutex
+	listeners []func(channels map[string]EventChannelInfo)
 }
 
 func NewEventRegistryFromFile(path string) (*EventRegistry, error) {
@@ -31,35 +137,262 @@ func NewEventRegistryFromFile(path string) (*EventRegistry, error) {
 
 // Reload перечитывает файл конфигурации каналов.
 func (r *EventRegistry) Reload() error {
+	chMap, err := r.Load()
+	if err != nil {
+		return err
+	}
+	r.Apply(chMap)
+	return nil
+}
+
+// FilePath возвращает путь к файлу конфигурации каналов.
+func (r *EventRegistry) FilePath() string {
+	return r.filePath
+}
+
+// Load читает и проверяет файл конфигурации каналов, не применяя его. Ошибки
+// содержимого файла оборачивают ErrInvalidConfig.
+func (r *EventRegistry) Load() (map[string]EventChannelInfo, error) {
 	f, err := os.Open(r.filePath)
 	if err != nil {
-		return fmt.Errorf("cannot open event registry config: %w", err)
+		return nil, fmt.Errorf("cannot open event registry config: %w", err)
 	}
 	defer f.Close()
 
 	dec := json.NewDecoder(f)
 	chMap := make(map[string]EventChannelInfo)
 	if err := dec.Decode(&chMap); err != nil {
-		return fmt.Errorf("cannot decode event registry config: %w", err)
+		return nil, fmt.Errorf("%w: cannot decode event registry config: %v", ErrInvalidConfig, err)
+	}
+
+	for name, info := range chMap {
+		normalized, err := normalizeChannel(info)
+		if err != nil {
+			return nil, fmt.Errorf("%w: channel %q: %v", ErrInvalidConfig, name, err)
+		}
+		chMap[name] = normalized
+	}
+	if _, err := kafkaTopicSettings(chMap); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	return chMap, nil
+}
+
+// normalizeChannel проверяет конфигурацию канала и заполняет endpoint/type из
+// primary destination. Если primary не отмечен, им считается первая destination.
+func normalizeChannel(info EventChannelInfo) (EventChannelInfo, error) {
+	if info.DefaultSchemaVersion != 0 && len(info.SchemaVersions) > 0 && !slices.Contains(info.SchemaVersions, info.DefaultSchemaVersion) {
+		return info, fmt.Errorf("default schema version %d is not in schema_versions %v", info.DefaultSchemaVersion, info.SchemaVersions)
+	}
+	switch info.DeliveryPolicy {
+	case "", DeliveryPolicyAll, DeliveryPolicyAny, DeliveryPolicyPrimary:
+	default:
+		return info, fmt.Errorf("unknown delivery policy %q", info.DeliveryPolicy)
+	}
+	if err := validatePartitionKey(info.PartitionKey); err != nil {
+		return info, err
+	}
+	if err := info.Kafka.validate(); err != nil {
+		return info, err
+	}
+	if err := info.Webhook.validate(); err != nil {
+		return info, err
+	}
+	switch info.CloudEvents {
+	case "", CloudEventsBinary, CloudEventsStructured:
+	default:
+		return info, fmt.Errorf("unknown cloudevents mode %q (want binary or structured)", info.CloudEvents)
+	}
+	switch info.Format {
+	case "", domain.PayloadFormatJSON, domain.PayloadFormatAvro, domain.PayloadFormatProtobuf:
+	default:
+		return info, fmt.Errorf("unknown payload format %q (want json, avro or protobuf)", info.Format)
+	}
+	if (info.Format == domain.PayloadFormatProtobuf) != (info.ProtoMessage != "") {
+		return info, errors.New("proto_message is required for protobuf format and allowed only with it")
+	}
+	if info.CloudEvents != "" && info.Format != "" && info.Format != domain.PayloadFormatJSON {
+		return info, fmt.Errorf("cloudevents requires json format, got %s", info.Format)
+	}
+	if w := info.TimestampWindow; w != nil && (w.MaxPastMs < 0 || w.MaxFutureMs < 0) {
+		return info, fmt.Errorf("timestamp_window limits must not be negative")
+	}
+	if len(info.Destinations) == 0 {
+		return info, nil
+	}
+
+	destinations := make([]ChannelDestination, len(info.Destinations))
+	copy(destinations, info.Destinations)
+	primary := -1
+	webhooks := make(map[string]int)
+	for i, d := range destinations {
+		if d.Type == "" || d.Endpoint == "" {
+			return info, fmt.Errorf("destination %d: type and endpoint are required", i)
+		}
+		if d.Webhook != nil && d.Type != "webhook" {
+			return info, fmt.Errorf("destination %d: webhook settings are allowed only for webhook destinations", i)
+		}
+		if err := d.Webhook.validate(); err != nil {
+			return info, fmt.Errorf("destination %d: %w", i, err)
+		}
+		// Настройки webhook ищутся по URL, поэтому URL в канале должен быть уникальным
+		if d.Type == "webhook" {
+			if j, ok := webhooks[d.Endpoint]; ok {
+				return info, fmt.Errorf("destinations %d and %d send to the same webhook %s", j, i, d.Endpoint)
+			}
+			webhooks[d.Endpoint] = i
+		}
+		if d.Primary {
+			if primary >= 0 {
+				return info, fmt.Errorf("destinations %d and %d are both marked primary", primary, i)
+			}
+			primary = i
+		}
+	}
+	if primary < 0 {
+		primary = 0
+		destinations[0].Primary = true
+	}
+
+	p := destinations[primary]
+	if (info.Endpoint != "" && info.Endpoint != p.Endpoint) || (info.Type != "" && info.Type != p.Type) {
+		return info, fmt.Errorf("endpoint %s/%s does not match primary destination %s/%s", info.Type, info.Endpoint, p.Type, p.Endpoint)
+	}
+	info.Endpoint = p.Endpoint
+	info.Type = p.Type
+	info.Destinations = destinations
+	return info, nil
+}
+
+// Save атомарно записывает карту каналов в файл конфигурации: во временный файл
+// в том же каталоге, затем rename поверх старого.
+func (r *EventRegistry) Save(chMap map[string]EventChannelInfo) error {
+	data, err := json.MarshalIndent(chMap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode event registry config: %w", err)
 	}
+	data = append(data, '\n')
 
+	tmp, err := os.CreateTemp(filepath.Dir(r.filePath), filepath.Base(r.filePath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("cannot create temp event registry config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot write event registry config: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot sync event registry config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot close event registry config: %w", err)
+	}
+	if info, err := os.Stat(r.filePath); err == nil {
+		os.Chmod(tmp.Name(), info.Mode().Perm())
+	}
+	if err := os.Rename(tmp.Name(), r.filePath); err != nil {
+		return fmt.Errorf("cannot replace event registry config: %w", err)
+	}
+	return nil
+}
+
+// Apply заменяет текущую карту каналов и уведомляет подписчиков OnChange.
+func (r *EventRegistry) Apply(chMap map[string]EventChannelInfo) {
 	r.mu.Lock()
-	defer r.mu.Unlock()
 	r.channels = chMap
+	listeners := slices.Clone(r.listeners)
+	r.mu.Unlock()
 	fmt.Println("[event-registry] config reloaded")
-	return nil
+
+	for _, listener := range listeners {
+		listener(r.GetAllChannels())
+	}
 }
 
+// OnChange регистрирует функцию, вызываемую после каждого Apply с новой картой каналов.
+func (r *EventRegistry) OnChange(listener func(channels map[string]EventChannelInfo)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// ResolveChannel возвращает primary endpoint и имя схемы канала.
 func (r *EventRegistry) ResolveChannel(channel string) (string, string, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	info, ok := r.channels[channel]
 	if !ok {
-		return "", "", fmt.Errorf("channel %q not found in event registry", channel)
+		return "", "", fmt.Errorf("%w: channel %q not found in event registry", domain.ErrUnknownEventType, channel)
 	}
 	return info.Endpoint, info.SchemaName, nil
 }
 
+func (r *EventRegistry) ResolveSchemaVersions(channel string) ([]int, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.channels[channel]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w: channel %q not found in event registry", domain.ErrUnknownEventType, channel)
+	}
+	return append([]int(nil), info.SchemaVersions...), info.DefaultSchemaVersion, nil
+}
+
+// ResolvePayloadFormat возвращает формат payload канала и, для Protobuf, полное имя сообщения.
+func (r *EventRegistry) ResolvePayloadFormat(channel string) (domain.PayloadFormat, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.channels[channel]
+	if !ok {
+		return "", "", fmt.Errorf("%w: channel %q not found in event registry", domain.ErrUnknownEventType, channel)
+	}
+	return info.PayloadFormat(), info.ProtoMessage, nil
+}
+
+// ResolvePartitionKey возвращает partition_key канала, если среди его destinations
+// есть kafka; для остальных каналов ключ не нужен.
+func (r *EventRegistry) ResolvePartitionKey(channel string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.channels[channel]
+	if !ok {
+		return "", fmt.Errorf("%w: channel %q not found in event registry", domain.ErrUnknownEventType, channel)
+	}
+	for _, d := range info.Targets() {
+		if d.Type == "kafka" {
+			return info.PartitionKey, nil
+		}
+	}
+	return "", nil
+}
+
+// ResolveTimestampWindow возвращает окно timestamp канала; без timestamp_window — без ограничений.
+func (r *EventRegistry) ResolveTimestampWindow(channel string) (domain.TimestampWindow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.channels[channel]
+	if !ok {
+		return domain.TimestampWindow{}, fmt.Errorf("%w: channel %q not found in event registry", domain.ErrUnknownEventType, channel)
+	}
+	if info.TimestampWindow == nil {
+		return domain.TimestampWindow{}, nil
+	}
+	return domain.TimestampWindow{
+		MaxPast:   time.Duration(info.TimestampWindow.MaxPastMs) * time.Millisecond,
+		MaxFuture: time.Duration(info.TimestampWindow.MaxFutureMs) * time.Millisecond,
+	}, nil
+}
+
+// GetChannel возвращает конфигурацию канала по имени.
+func (r *EventRegistry) GetChannel(channel string) (EventChannelInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.channels[channel]
+	return info, ok
+}
+
 // GetAllChannels возвращает копию текущей карты каналов (для просмотра через API).
 func (r *EventRegistry) GetAllChannels() map[string]EventChannelInfo {
 	r.mu.RLock()
@@ -0,0 +1,77 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"event-system/internal/domain"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// FilePublisher дописывает события в файлы JSON Lines. Endpoint канала — путь
+// к файлу относительно baseDir.
+type FilePublisher struct {
+	registry *EventRegistry
+	baseDir  string
+	mu       sync.Mutex
+}
+
+func NewFilePublisher(registry *EventRegistry, baseDir string) *FilePublisher {
+	return &FilePublisher{registry: registry, baseDir: baseDir}
+}
+
+func (p *FilePublisher) Publish(ctx context.Context, event *domain.Event) error {
+	endpoint, _, err := p.registry.ResolveChannel(event.Type)
+	if err != nil {
+		return fmt.Errorf("failed to resolve channel for event type %s: %w", event.Type, err)
+	}
+	return p.PublishTo(ctx, endpoint, event)
+}
+
+func (p *FilePublisher) PublishTo(ctx context.Context, endpoint string, event *domain.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	path, err := p.resolvePath(endpoint)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(domain.NewEventJSON(event))
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open event file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write event to %s: %w", path, err)
+	}
+
+	log.Printf("📄 Event %s written to file: %s", event.Type, path)
+	return nil
+}
+
+// resolvePath не дает endpoint выйти за пределы baseDir.
+func (p *FilePublisher) resolvePath(endpoint string) (string, error) {
+	path := filepath.Join(p.baseDir, filepath.Clean("/"+endpoint))
+	rel, err := filepath.Rel(p.baseDir, path)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("invalid file endpoint %q", endpoint)
+	}
+	return path, nil
+}
@@ -0,0 +1,227 @@
+package infrastructure
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultIdempotencyWindow   = 24 * time.Hour
+	defaultIdempotencyPending  = time.Minute
+	defaultIdempotencyCapacity = 100_000
+)
+
+// IdempotencyConfig — настройки хранилища принятых событий. Нулевые поля получают значения по умолчанию.
+type IdempotencyConfig struct {
+	Window         time.Duration // сколько помнить принятое событие (по умолчанию 24h)
+	PendingTimeout time.Duration // через сколько освобождается ключ, если обработка не завершилась (по умолчанию 1m)
+	Capacity       int           // предел записей in-memory LRU (по умолчанию 100000)
+}
+
+func (c IdempotencyConfig) withDefaults() IdempotencyConfig {
+	if c.Window <= 0 {
+		c.Window = defaultIdempotencyWindow
+	}
+	if c.PendingTimeout <= 0 {
+		c.PendingTimeout = defaultIdempotencyPending
+	}
+	if c.Capacity <= 0 {
+		c.Capacity = defaultIdempotencyCapacity
+	}
+	return c
+}
+
+// MemoryIdempotencyStore — LRU принятых событий с TTL. Не переживает рестарт;
+// при переполнении вытесняются давно не использованные ключи.
+type MemoryIdempotencyStore struct {
+	cfg IdempotencyConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	order   *list.List // от недавних к давним
+	entries map[string]*list.Element
+}
+
+type idempotencyEntry struct {
+	record    domain.IdempotencyRecord
+	expiresAt time.Time
+}
+
+func NewMemoryIdempotencyStore(cfg IdempotencyConfig) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		cfg:     cfg.withDefaults(),
+		now:     time.Now,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, record domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if el, ok := s.entries[record.Key]; ok {
+		entry := el.Value.(*idempotencyEntry)
+		if now.Before(entry.expiresAt) {
+			s.order.MoveToFront(el)
+			existing := entry.record
+			return &existing, false, nil
+		}
+		s.remove(el)
+	}
+
+	record.AcceptedAt = time.Time{}
+	s.entries[record.Key] = s.order.PushFront(&idempotencyEntry{record: record, expiresAt: now.Add(s.cfg.PendingTimeout)})
+	for s.order.Len() > s.cfg.Capacity {
+		s.remove(s.order.Back())
+	}
+	return nil, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, acceptedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.entries[key]
+	if !ok {
+		// Ключ вытеснен или истек, пока событие обрабатывалось
+		return nil
+	}
+	entry := el.Value.(*idempotencyEntry)
+	entry.record.AcceptedAt = acceptedAt
+	entry.expiresAt = acceptedAt.Add(s.cfg.Window)
+	s.order.MoveToFront(el)
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.entries[key]; ok && el.Value.(*idempotencyEntry).record.Pending() {
+		s.remove(el)
+	}
+	return nil
+}
+
+// Len возвращает число запомненных ключей, включая еще не вытесненные истекшие.
+func (s *MemoryIdempotencyStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+func (s *MemoryIdempotencyStore) remove(el *list.Element) {
+	s.order.Remove(el)
+	delete(s.entries, el.Value.(*idempotencyEntry).record.Key)
+}
+
+const idempotencySchema = `
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	key         TEXT    NOT NULL PRIMARY KEY,
+	event_id    TEXT    NOT NULL,
+	fingerprint TEXT    NOT NULL,
+	accepted_at INTEGER,
+	expires_at  INTEGER NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires ON idempotency_keys (expires_at);
+`
+
+// SQLiteIdempotencyStore хранит принятые события в SQLite: дедупликация
+// переживает рестарт и работает для нескольких процессов с общей базой.
+type SQLiteIdempotencyStore struct {
+	db  *sql.DB
+	cfg IdempotencyConfig
+	now func() time.Time
+}
+
+func NewSQLiteIdempotencyStore(dsn string, cfg IdempotencyConfig) (*SQLiteIdempotencyStore, error) {
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", dsn, err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(idempotencySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create idempotency schema: %w", err)
+	}
+
+	return &SQLiteIdempotencyStore{
+		db:  db,
+		cfg: cfg.withDefaults(),
+		now: time.Now,
+	}, nil
+}
+
+func (s *SQLiteIdempotencyStore) Reserve(ctx context.Context, record domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error) {
+	now := s.now().UTC().UnixNano()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin idempotency transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		existing   = domain.IdempotencyRecord{Key: record.Key}
+		acceptedAt sql.NullInt64
+	)
+	err = tx.QueryRowContext(ctx,
+		`SELECT event_id, fingerprint, accepted_at FROM idempotency_keys WHERE key = ? AND expires_at > ?`,
+		record.Key, now,
+	).Scan(&existing.EventID, &existing.Fingerprint, &acceptedAt)
+	switch {
+	case err == nil:
+		if acceptedAt.Valid {
+			existing.AcceptedAt = time.Unix(0, acceptedAt.Int64).UTC()
+		}
+		return &existing, false, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, false, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+
+	// Заодно удаляем истекшие ключи, включая прежнюю запись этого ключа
+	if _, err := tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= ?`, now); err != nil {
+		return nil, false, fmt.Errorf("failed to prune idempotency keys: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO idempotency_keys (key, event_id, fingerprint, expires_at) VALUES (?, ?, ?, ?)`,
+		record.Key, record.EventID, record.Fingerprint, now+s.cfg.PendingTimeout.Nanoseconds(),
+	); err != nil {
+		return nil, false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit idempotency key: %w", err)
+	}
+	return nil, true, nil
+}
+
+func (s *SQLiteIdempotencyStore) Complete(ctx context.Context, key string, acceptedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx,
+		`UPDATE idempotency_keys SET accepted_at = ?, expires_at = ? WHERE key = ?`,
+		acceptedAt.UTC().UnixNano(), acceptedAt.Add(s.cfg.Window).UTC().UnixNano(), key,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteIdempotencyStore) Release(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = ? AND accepted_at IS NULL`, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLiteIdempotencyStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,134 @@
+package infrastructure
+
+import (
+	"context"
+	"event-system/internal/domain"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyStores_ReserveCompleteRelease(t *testing.T) {
+	for name, setup := range idempotencyStores() {
+		t.Run(name, func(t *testing.T) {
+			store, clock := setup(t)
+			ctx := context.Background()
+			record := domain.IdempotencyRecord{Key: "event:evt-1", EventID: "evt-1", Fingerprint: "abc"}
+
+			_, reserved, err := store.Reserve(ctx, record)
+			assertNoError(t, err)
+			if !reserved {
+				t.Fatal("expected first reserve to succeed")
+			}
+			existing, reserved, err := store.Reserve(ctx, record)
+			assertNoError(t, err)
+			if reserved || existing == nil || !existing.Pending() {
+				t.Fatalf("expected pending record for concurrent retry, got %+v", existing)
+			}
+
+			// Released key can be reserved again
+			assertNoError(t, store.Release(ctx, record.Key))
+			_, reserved, _ = store.Reserve(ctx, record)
+			if !reserved {
+				t.Fatal("expected reserve after release to succeed")
+			}
+
+			acceptedAt := clock.now().UTC()
+			assertNoError(t, store.Complete(ctx, record.Key, acceptedAt))
+			assertNoError(t, store.Release(ctx, record.Key)) // no-op for accepted keys
+			existing, reserved, err = store.Reserve(ctx, record)
+			assertNoError(t, err)
+			if reserved || existing.Pending() || existing.EventID != "evt-1" || !existing.AcceptedAt.Equal(acceptedAt) {
+				t.Fatalf("expected accepted record, got %+v", existing)
+			}
+
+			// After the window the key is forgotten
+			clock.advance(time.Hour + time.Second)
+			if _, reserved, _ := store.Reserve(ctx, record); !reserved {
+				t.Error("expected key to expire after the window")
+			}
+		})
+	}
+}
+
+func TestIdempotencyStores_PendingTimeout(t *testing.T) {
+	for name, setup := range idempotencyStores() {
+		t.Run(name, func(t *testing.T) {
+			store, clock := setup(t)
+			record := domain.IdempotencyRecord{Key: "key:k1", EventID: "evt-1"}
+
+			store.Reserve(context.Background(), record)
+			clock.advance(2 * time.Minute)
+
+			if _, reserved, _ := store.Reserve(context.Background(), record); !reserved {
+				t.Error("expected abandoned reservation to expire")
+			}
+		})
+	}
+}
+
+func TestMemoryIdempotencyStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewMemoryIdempotencyStore(IdempotencyConfig{Capacity: 2})
+	ctx := context.Background()
+	for _, key := range []string{"a", "b"} {
+		store.Reserve(ctx, domain.IdempotencyRecord{Key: key})
+		store.Complete(ctx, key, time.Now())
+	}
+	store.Reserve(ctx, domain.IdempotencyRecord{Key: "a"}) // touch a
+	store.Reserve(ctx, domain.IdempotencyRecord{Key: "c"})
+
+	if store.Len() != 2 {
+		t.Fatalf("expected 2 keys, got %d", store.Len())
+	}
+	if _, reserved, _ := store.Reserve(ctx, domain.IdempotencyRecord{Key: "a"}); reserved {
+		t.Error("recently used key must not be evicted")
+	}
+	if _, reserved, _ := store.Reserve(ctx, domain.IdempotencyRecord{Key: "b"}); !reserved {
+		t.Error("expected least recently used key to be evicted")
+	}
+}
+
+func TestSQLiteIdempotencyStore_SurvivesRestart(t *testing.T) {
+	dsn := filepath.Join(t.TempDir(), "dedup.db")
+	store, err := NewSQLiteIdempotencyStore(dsn, IdempotencyConfig{})
+	assertNoError(t, err)
+	store.Reserve(context.Background(), domain.IdempotencyRecord{Key: "event:evt-1", EventID: "evt-1"})
+	assertNoError(t, store.Complete(context.Background(), "event:evt-1", time.Now()))
+	store.Close()
+
+	reopened, err := NewSQLiteIdempotencyStore(dsn, IdempotencyConfig{})
+	assertNoError(t, err)
+	defer reopened.Close()
+	if _, reserved, _ := reopened.Reserve(context.Background(), domain.IdempotencyRecord{Key: "event:evt-1"}); reserved {
+		t.Error("expected accepted key to survive restart")
+	}
+}
+
+// === Test Helpers ===
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func idempotencyStores() map[string]func(t *testing.T) (domain.IdempotencyStore, *fakeClock) {
+	cfg := IdempotencyConfig{Window: time.Hour, PendingTimeout: time.Minute}
+	return map[string]func(t *testing.T) (domain.IdempotencyStore, *fakeClock){
+		"memory": func(t *testing.T) (domain.IdempotencyStore, *fakeClock) {
+			clock := &fakeClock{t: time.Now()}
+			store := NewMemoryIdempotencyStore(cfg)
+			store.now = clock.now
+			return store, clock
+		},
+		"sqlite": func(t *testing.T) (domain.IdempotencyStore, *fakeClock) {
+			clock := &fakeClock{t: time.Now()}
+			store, err := NewSQLiteIdempotencyStore(":memory:", cfg)
+			if err != nil {
+				t.Fatalf("failed to create sqlite idempotency store: %v", err)
+			}
+			t.Cleanup(func() { store.Close() })
+			store.now = clock.now
+			return store, clock
+		},
+	}
+}
@@ -0,0 +1,253 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// EventDispatcher — получатель событий, прочитанных консьюмером (см. application.EventDispatcher).
+type EventDispatcher interface {
+	Dispatch(ctx context.Context, event *domain.Event) error
+	EventTypes() []string
+}
+
+// messageReader — подмножество *kafka.Reader, которое нужно консьюмеру.
+type messageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+type KafkaConsumerConfig struct {
+	Brokers      []string
+	GroupID      string        // consumer group; у каждой группы свои offsets
+	MaxRetries   int           // повторы обработчика перед тем, как пропустить сообщение
+	RetryBackoff time.Duration // пауза между повторами
+	Payloads     PayloadCodec  // декодирует payload каналов с форматом avro и protobuf
+}
+
+// KafkaConsumer читает топики, на которые EventRegistry отображает типы событий
+// с подписчиками, и передает декодированные события в EventDispatcher.
+type KafkaConsumer struct {
+	cfg        KafkaConsumerConfig
+	registry   *EventRegistry
+	dispatcher EventDispatcher
+	newReader  func(topic string) messageReader
+
+	wg sync.WaitGroup
+}
+
+func NewKafkaConsumer(cfg KafkaConsumerConfig, registry *EventRegistry, dispatcher EventDispatcher) *KafkaConsumer {
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 500 * time.Millisecond
+	}
+	c := &KafkaConsumer{
+		cfg:        cfg,
+		registry:   registry,
+		dispatcher: dispatcher,
+	}
+	c.newReader = func(topic string) messageReader {
+		return kafka.NewReader(kafka.ReaderConfig{
+			Brokers:  cfg.Brokers,
+			GroupID:  cfg.GroupID,
+			Topic:    topic,
+			MinBytes: 1,
+			MaxBytes: 10e6,
+			MaxWait:  time.Second,
+		})
+	}
+	return c
+}
+
+// Topics возвращает топики всех kafka-destinations каналов, на типы событий которых
+// есть подписчики. Destinations других типов (webhook, sqlite, file) пропускаются.
+func (c *KafkaConsumer) Topics() ([]string, error) {
+	seen := make(map[string]bool)
+	var topics []string
+	for _, eventType := range c.dispatcher.EventTypes() {
+		info, ok := c.registry.GetChannel(eventType)
+		if !ok {
+			return nil, fmt.Errorf("%w: channel %q not found in event registry", domain.ErrUnknownEventType, eventType)
+		}
+		for _, d := range info.Targets() {
+			if d.Type != "kafka" || seen[d.Endpoint] {
+				continue
+			}
+			seen[d.Endpoint] = true
+			topics = append(topics, d.Endpoint)
+		}
+	}
+	return topics, nil
+}
+
+// Start запускает по одному reader на топик. Чтение прекращается при отмене ctx;
+// Wait дожидается завершения всех reader'ов.
+func (c *KafkaConsumer) Start(ctx context.Context) error {
+	topics, err := c.Topics()
+	if err != nil {
+		return err
+	}
+
+	for _, topic := range topics {
+		reader := c.newReader(topic)
+		c.wg.Add(1)
+		go func(topic string) {
+			defer c.wg.Done()
+			defer reader.Close()
+			c.consume(ctx, topic, reader)
+		}(topic)
+		log.Printf("👂 Consumer group %s subscribed to topic: %s", c.cfg.GroupID, topic)
+	}
+	return nil
+}
+
+// Wait блокируется, пока все reader'ы не остановятся и не будут закрыты.
+func (c *KafkaConsumer) Wait() {
+	c.wg.Wait()
+}
+
+func (c *KafkaConsumer) consume(ctx context.Context, topic string, reader messageReader) {
+	for {
+		msg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return
+			}
+			log.Printf("kafka fetch error on topic %s: %v", topic, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.cfg.RetryBackoff):
+			}
+			continue
+		}
+
+		c.handleMessage(ctx, topic, msg)
+
+		if err := reader.CommitMessages(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("kafka commit error on topic %s: %v", topic, err)
+		}
+	}
+}
+
+// handleMessage декодирует и диспатчит одно сообщение. Некорректные и невалидные
+// сообщения не повторяются; ошибки обработчиков повторяются до MaxRetries раз.
+// Повтор снова вызывает все обработчики типа, включая уже отработавшие, поэтому
+// обработчики должны быть идемпотентными (см. domain.EventHandler).
+func (c *KafkaConsumer) handleMessage(ctx context.Context, topic string, msg kafka.Message) {
+	event, err := decodeEventMessage(msg, c.cfg.Payloads)
+	if err != nil {
+		log.Printf("skipping malformed message on topic %s (offset %d): %v", topic, msg.Offset, err)
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.dispatcher.Dispatch(ctx, event)
+		if err == nil {
+			return
+		}
+		var validationErr *domain.EventValidationError
+		if errors.As(err, &validationErr) || attempt >= c.cfg.MaxRetries {
+			log.Printf("skipping event %s from topic %s after %d attempt(s): %v", event.ID, topic, attempt, err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(c.cfg.RetryBackoff):
+		}
+	}
+}
+
+// decodeEventMessage восстанавливает domain.Event из JSON, записанного KafkaPublisher,
+// из CloudEvent в binary (заголовки ce_*) или structured режиме или из Avro/Protobuf payload.
+func decodeEventMessage(msg kafka.Message, payloads PayloadCodec) (*domain.Event, error) {
+	if event, ok, err := decodeCloudEventMessage(msg); ok {
+		return event, err
+	}
+	if event, ok, err := decodeEncodedPayloadMessage(msg, payloads); ok {
+		return event, err
+	}
+
+	// Ключи ID, Type, Timestamp, Payload разбираются без учета регистра, поэтому
+	// сообщения с ключами id, type, timestamp, payload тоже читаются; версия схемы
+	// в таком случае берется из заголовка schema-version.
+	var event domain.Event
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+	if event.SchemaVersion == 0 {
+		for _, h := range msg.Headers {
+			if h.Key == "schema-version" {
+				event.SchemaVersion, _ = strconv.Atoi(string(h.Value))
+			}
+		}
+	}
+	if event.Type == "" {
+		for _, h := range msg.Headers {
+			if h.Key == "event-type" {
+				event.Type = string(h.Value)
+			}
+		}
+	}
+	if event.Type == "" {
+		return nil, errors.New("event type is missing")
+	}
+	if event.Metadata == nil {
+		event.Metadata = metadataFromHeaders(msg.Headers)
+	}
+	return &event, nil
+}
+
+// decodeCloudEventMessage разбирает сообщение, если оно в формате CloudEvents; ok — формат распознан.
+func decodeCloudEventMessage(msg kafka.Message) (*domain.Event, bool, error) {
+	var contentType string
+	attrs := make(map[string]string)
+	for _, h := range msg.Headers {
+		if name, ok := strings.CutPrefix(strings.ToLower(h.Key), "ce_"); ok {
+			attrs[name] = string(h.Value)
+		} else if strings.EqualFold(h.Key, "content-type") {
+			contentType = string(h.Value)
+		}
+	}
+	switch {
+	case attrs["specversion"] != "":
+		event, err := DecodeBinaryCloudEvent(attrs, contentType, msg.Value)
+		return event, true, err
+	case strings.HasPrefix(contentType, CloudEventsContentType):
+		event, err := DecodeStructuredCloudEvent(msg.Value)
+		return event, true, err
+	}
+	return nil, false, nil
+}
+
+// metadataFromHeaders восстанавливает стандартные поля метаданных из заголовков
+// сообщений, записанных не KafkaPublisher (в теле нет metadata). Прочие заголовки
+// чужих producer'ов не переносятся: их имена могут не пройти валидацию.
+func metadataFromHeaders(headers []kafka.Header) *domain.EventMetadata {
+	m := &domain.EventMetadata{}
+	for _, h := range headers {
+		m.SetField(h.Key, string(h.Value))
+	}
+	if m.IsEmpty() {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,193 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"event-system/internal/domain"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestKafkaConsumer_DispatchesDecodedEvents(t *testing.T) {
+	dispatcher := &fakeDispatcher{types: []string{"OrderStatusEvent"}}
+	consumer := NewKafkaConsumer(KafkaConsumerConfig{GroupID: "test"}, createTestRegistry(t), dispatcher)
+
+	event := createOrderEvent("evt-1", time.Now().UTC())
+	reader := newFakeReader(encodeEventMessage(t, event), kafka.Message{Value: []byte("not json")})
+	consumer.newReader = func(topic string) messageReader {
+		if topic != "orders-topic" {
+			t.Errorf("expected subscription to 'orders-topic', got '%s'", topic)
+		}
+		return reader
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	assertNoError(t, consumer.Start(ctx))
+	reader.waitDrained(t)
+	cancel()
+	consumer.Wait()
+
+	if len(dispatcher.events) != 1 || dispatcher.events[0].ID != "evt-1" {
+		t.Fatalf("expected evt-1 to be dispatched, got %+v", dispatcher.events)
+	}
+	if dispatcher.events[0].Payload["order_id"] != "12345" {
+		t.Errorf("expected payload to survive decoding, got %v", dispatcher.events[0].Payload)
+	}
+	if reader.committed != 2 {
+		t.Errorf("expected both messages committed (malformed one skipped), got %d", reader.committed)
+	}
+	if !reader.closed {
+		t.Error("expected reader to be closed on shutdown")
+	}
+}
+
+func TestKafkaConsumer_TopicsCoverAllKafkaDestinations(t *testing.T) {
+	registry := createTestRegistryFromConfig(t, `{
+		"OrderStatusEvent": {"schema": "order_status_notification", "destinations": [
+			{"type": "webhook", "endpoint": "http://example.com/hook", "primary": true},
+			{"type": "kafka", "endpoint": "orders-topic"},
+			{"type": "kafka", "endpoint": "orders-audit"}
+		]},
+		"PaymentEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order_status_notification"}
+	}`)
+	dispatcher := &fakeDispatcher{types: []string{"OrderStatusEvent", "PaymentEvent"}}
+	consumer := NewKafkaConsumer(KafkaConsumerConfig{}, registry, dispatcher)
+
+	topics, err := consumer.Topics()
+	assertNoError(t, err)
+	if !reflect.DeepEqual(topics, []string{"orders-topic", "orders-audit"}) {
+		t.Errorf("expected every kafka destination once and no webhook URL, got %v", topics)
+	}
+}
+
+func TestDecodeEventMessage_AcceptsSnakeCaseKeys(t *testing.T) {
+	msg := kafka.Message{
+		Value: []byte(`{"id": "evt-1", "type": "OrderStatusEvent", "timestamp": "2024-05-01T12:00:00Z", "schema_version": 2, "payload": {"order_id": "12345"}}`),
+		Headers: []kafka.Header{
+			{Key: "event-type", Value: []byte("OrderStatusEvent")},
+			{Key: "schema-version", Value: []byte("2")},
+		},
+	}
+
+	event, err := decodeEventMessage(msg, nil)
+	assertNoError(t, err)
+	if event.ID != "evt-1" || event.Type != "OrderStatusEvent" || event.Payload["order_id"] != "12345" {
+		t.Errorf("unexpected decoded event: %+v", event)
+	}
+	if !event.Timestamp.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected timestamp to be decoded, got %v", event.Timestamp)
+	}
+	if event.SchemaVersion != 2 {
+		t.Errorf("expected schema version from header, got %d", event.SchemaVersion)
+	}
+}
+
+func TestKafkaConsumer_RetriesHandlerErrors(t *testing.T) {
+	dispatcher := &fakeDispatcher{types: []string{"OrderStatusEvent"}, err: errors.New("handler down")}
+	consumer := NewKafkaConsumer(KafkaConsumerConfig{MaxRetries: 3, RetryBackoff: time.Millisecond}, createTestRegistry(t), dispatcher)
+
+	consumer.handleMessage(context.Background(), "orders-topic", encodeEventMessage(t, createOrderEvent("evt-1", time.Now())))
+
+	if dispatcher.calls != 3 {
+		t.Fatalf("expected 3 dispatch attempts, got %d", dispatcher.calls)
+	}
+}
+
+func TestKafkaConsumer_DoesNotRetryValidationErrors(t *testing.T) {
+	dispatcher := &fakeDispatcher{types: []string{"OrderStatusEvent"}, err: domain.NewEventValidationError("bad payload")}
+	consumer := NewKafkaConsumer(KafkaConsumerConfig{MaxRetries: 3, RetryBackoff: time.Millisecond}, createTestRegistry(t), dispatcher)
+
+	consumer.handleMessage(context.Background(), "orders-topic", encodeEventMessage(t, createOrderEvent("evt-1", time.Now())))
+
+	if dispatcher.calls != 1 {
+		t.Fatalf("expected a single dispatch attempt, got %d", dispatcher.calls)
+	}
+}
+
+// === Test Helpers ===
+
+func encodeEventMessage(t *testing.T, event *domain.Event) kafka.Message {
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+	return kafka.Message{Value: data, Headers: []kafka.Header{{Key: "event-type", Value: []byte(event.Type)}}}
+}
+
+type fakeDispatcher struct {
+	types  []string
+	err    error
+	calls  int
+	events []*domain.Event
+}
+
+func (d *fakeDispatcher) Dispatch(ctx context.Context, event *domain.Event) error {
+	d.calls++
+	if d.err != nil {
+		return d.err
+	}
+	d.events = append(d.events, event)
+	return nil
+}
+
+func (d *fakeDispatcher) EventTypes() []string { return d.types }
+
+// fakeReader serves queued messages and then blocks until the context is cancelled.
+type fakeReader struct {
+	mu        sync.Mutex
+	messages  []kafka.Message
+	committed int
+	closed    bool
+	drained   chan struct{}
+}
+
+func newFakeReader(messages ...kafka.Message) *fakeReader {
+	return &fakeReader{messages: messages, drained: make(chan struct{})}
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+	if len(r.messages) > 0 {
+		msg := r.messages[0]
+		r.messages = r.messages[1:]
+		r.mu.Unlock()
+		return msg, nil
+	}
+	r.mu.Unlock()
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed += len(msgs)
+	if len(r.messages) == 0 {
+		select {
+		case <-r.drained:
+		default:
+			close(r.drained)
+		}
+	}
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+func (r *fakeReader) waitDrained(t *testing.T) {
+	select {
+	case <-r.drained:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for messages to be consumed")
+	}
+}
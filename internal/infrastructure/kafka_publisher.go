@@ -3,102 +3,262 @@ package infrastructure
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"event-system/internal/domain"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/segmentio/kafka-go"
 )
 
+const defaultKafkaPublishTimeout = 5 * time.Second
+
 type KafkaPublisher struct {
 	brokers  []string
-	writers  map[string]*kafka.Writer // кэш writers для топиков
 	registry *EventRegistry
+	writers  *writerPool // writers по топикам
+
+	// Payloads кодирует payload каналов с форматом avro и protobuf; без него такие каналы не публикуются
+	Payloads PayloadCodec
+
+	asyncFailures atomic.Int64 // сообщения, потерянные async-writers
 }
 
-// NewKafkaPublisher создает publisher с подключением к Kafka cluster
+// NewKafkaPublisher создает publisher с подключением к Kafka cluster.
+// При перезагрузке каналов writers удаленных топиков закрываются, а перенастроенных — пересоздаются.
 func NewKafkaPublisher(brokers []string, registry *EventRegistry) *KafkaPublisher {
-	return &KafkaPublisher{
+	kp := &KafkaPublisher{
 		brokers:  brokers,
-		writers:  make(map[string]*kafka.Writer),
 		registry: registry,
 	}
+	kp.writers = newWriterPool(func(topic string, cfg KafkaWriterConfig) messageWriter {
+		return cfg.newWriter(kp.brokers, topic, func(n int) { kp.asyncFailures.Add(int64(n)) })
+	})
+	registry.OnChange(kp.onChannelsChanged)
+	return kp
 }
 
-func (kp *KafkaPublisher) Publish(event *domain.Event) error {
+func (kp *KafkaPublisher) Publish(ctx context.Context, event *domain.Event) error {
 	topic, _, err := kp.registry.ResolveChannel(event.Type)
 	if err != nil {
 		return fmt.Errorf("failed to resolve channel for event type %s: %w", event.Type, err)
 	}
+	return kp.PublishTo(ctx, topic, event)
+}
 
-	writer, err := kp.getOrCreateWriter(topic)
+// PublishTo публикует событие в указанный топик.
+func (kp *KafkaPublisher) PublishTo(ctx context.Context, topic string, event *domain.Event) error {
+	msg, err := kp.buildMessage(event)
 	if err != nil {
-		return fmt.Errorf("failed to get writer for topic %s: %w", topic, err)
+		return err
 	}
 
-	data, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
+	if err := kp.write(ctx, topic, msg); err != nil {
+		log.Printf("kafka publish error to topic %s: %v", topic, err)
+		return kafkaPublishError(topic, err)
 	}
 
-	msg := kafka.Message{
-		Key:   nil,
-		Value: data,
-		Time:  event.Timestamp,
-		Headers: []kafka.Header{
-			{Key: "event-type", Value: []byte(event.Type)},
-		},
+	log.Printf("✅ Event %s published to Kafka topic: %s", event.Type, topic)
+	return nil
+}
+
+// PublishBatchTo публикует события в топик одним вызовом WriteMessages, сохраняя порядок.
+// Возвращает ошибку по каждому событию: kafka.WriteErrors разбирается по сообщениям.
+func (kp *KafkaPublisher) PublishBatchTo(ctx context.Context, topic string, events []*domain.Event) []error {
+	errs := make([]error, len(events))
+	msgs := make([]kafka.Message, 0, len(events))
+	indexes := make([]int, 0, len(events))
+	for i, event := range events {
+		msg, err := kp.buildMessage(event)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		msgs = append(msgs, msg)
+		indexes = append(indexes, i)
+	}
+	if len(msgs) == 0 {
+		return errs
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	err := kp.write(ctx, topic, msgs...)
+	var writeErrs kafka.WriteErrors
+	perMessage := errors.As(err, &writeErrs) && len(writeErrs) == len(msgs)
+	for n, i := range indexes {
+		msgErr := err
+		if perMessage {
+			msgErr = writeErrs[n]
+		}
+		if msgErr != nil {
+			errs[i] = kafkaPublishError(topic, msgErr)
+		}
+	}
+	if err != nil {
+		log.Printf("kafka batch publish error to topic %s: %v", topic, err)
+	} else {
+		log.Printf("✅ %d events published to Kafka topic: %s", len(msgs), topic)
+	}
+	return errs
+}
 
-	if err := writer.WriteMessages(ctx, msg); err != nil {
-		log.Printf("kafka publish error to topic %s: %v", topic, err)
-		return fmt.Errorf("failed to publish to kafka topic %s: %w", topic, err)
+// kafkaPublishError оборачивает ошибку записи в топик. Ошибки, которые брокер вернет
+// и при повторе (kafka.Error с !Temporary(): MessageSizeTooLarge, TopicAuthorizationFailed, ...),
+// помечаются domain.ErrPublishRejected.
+func kafkaPublishError(topic string, err error) error {
+	if rejectedByKafka(err) {
+		return fmt.Errorf("failed to publish to kafka topic %s: %w: %w", topic, domain.ErrPublishRejected, err)
 	}
+	return fmt.Errorf("failed to publish to kafka topic %s: %w", topic, err)
+}
 
-	log.Printf("✅ Event %s published to Kafka topic: %s", event.Type, topic)
-	return nil
+// rejectedByKafka сообщает, что брокер окончательно отклонил запись; kafka.WriteErrors —
+// если окончательно отклонены все не записанные сообщения.
+func rejectedByKafka(err error) bool {
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) {
+		rejected := false
+		for _, e := range writeErrs {
+			if e == nil {
+				continue
+			}
+			if !rejectedByKafka(e) {
+				return false
+			}
+			rejected = true
+		}
+		return rejected
+	}
+	var kafkaErr kafka.Error
+	return errors.As(err, &kafkaErr) && !kafkaErr.Temporary()
 }
 
-// getOrCreateWriter получает существующий writer или создает новый для топика
-func (kp *KafkaPublisher) getOrCreateWriter(topic string) (*kafka.Writer, error) {
-	// Проверяем кэш
-	if writer, exists := kp.writers[topic]; exists {
-		return writer, nil
-	}
-
-	// Создаем новый writer для топика
-	writer := kafka.NewWriter(kafka.WriterConfig{
-		Brokers:  kp.brokers,
-		Topic:    topic,
-		Balancer: &kafka.LeastBytes{}, // Распределение по партициям
-		// Настройки производительности
-		BatchSize:    1, // Для тестирования отправляем сразу
-		BatchTimeout: 10 * time.Millisecond,
-		RequiredAcks: 1,
-		Async:        false,
+// write отправляет сообщения через writer топика. Если у ctx нет дедлайна,
+// запись ограничивается defaultKafkaPublishTimeout.
+func (kp *KafkaPublisher) write(ctx context.Context, topic string, msgs ...kafka.Message) error {
+	writer, release, err := kp.writers.acquire(topic, func() (KafkaWriterConfig, error) {
+		return kp.topicSettings(topic)
 	})
+	if err != nil {
+		return fmt.Errorf("failed to get writer for topic %s: %w", topic, err)
+	}
+	defer release()
 
-	// Сохраняем в кэш
-	kp.writers[topic] = writer
-
-	log.Printf("📝 Created new Kafka writer for topic: %s", topic)
-	return writer, nil
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultKafkaPublishTimeout)
+		defer cancel()
+	}
+	return writer.WriteMessages(ctx, msgs...)
 }
 
-// Close закрывает все writers
-func (kp *KafkaPublisher) Close() error {
-	for topic, writer := range kp.writers {
-		if err := writer.Close(); err != nil {
-			log.Printf("error closing writer for topic %s: %v", topic, err)
+// buildMessage собирает сообщение Kafka; ключ берется из partition_key канала,
+// чтобы события с одним ключом попадали в одну партицию. Метаданные события
+// дублируются в заголовках, чтобы их можно было читать без разбора тела.
+// Каналы с cloudevents кодируются по Kafka protocol binding CloudEvents, каналы
+// с форматом avro и protobuf — по своей схеме (см. encodedPayloadMessage).
+func (kp *KafkaPublisher) buildMessage(event *domain.Event) (kafka.Message, error) {
+	info, _ := kp.registry.GetChannel(event.Type)
+	key, err := domain.PartitionKey(info.PartitionKey, event)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	msg := kafka.Message{Key: key, Time: event.Timestamp}
+
+	switch info.CloudEvents {
+	case CloudEventsBinary:
+		if msg.Value, err = json.Marshal(event.Payload); err != nil {
+			return kafka.Message{}, fmt.Errorf("failed to marshal event payload: %w", err)
+		}
+		attrs := cloudEventAttributes(event)
+		names := make([]string, 0, len(attrs))
+		for name := range attrs {
+			if name != "datacontenttype" {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		msg.Headers = []kafka.Header{{Key: "content-type", Value: []byte(attrs["datacontenttype"])}}
+		for _, name := range names {
+			msg.Headers = append(msg.Headers, kafka.Header{Key: "ce_" + name, Value: []byte(attrs[name])})
 		}
+	case CloudEventsStructured:
+		if msg.Value, err = EncodeStructuredCloudEvent(event); err != nil {
+			return kafka.Message{}, fmt.Errorf("failed to marshal cloud event: %w", err)
+		}
+		msg.Headers = []kafka.Header{{Key: "content-type", Value: []byte(CloudEventsContentType)}}
+	default:
+		if format := info.PayloadFormat(); format != domain.PayloadFormatJSON {
+			if err := kp.encodedPayloadMessage(&msg, event, format); err != nil {
+				return kafka.Message{}, err
+			}
+			break
+		}
+		if msg.Value, err = json.Marshal(event); err != nil {
+			return kafka.Message{}, fmt.Errorf("failed to marshal event: %w", err)
+		}
+		msg.Headers = append([]kafka.Header{
+			{Key: "event-type", Value: []byte(event.Type)},
+			{Key: "schema-version", Value: []byte(strconv.Itoa(event.SchemaVersion))},
+		}, metadataHeaders(event.Metadata)...)
 	}
+	return msg, nil
+}
+
+// metadataHeaders переносит метаданные события в заголовки сообщения: стандартные
+// поля — под своими именами (correlation-id, source, ...), свободные заголовки — как есть.
+func metadataHeaders(m *domain.EventMetadata) []kafka.Header {
+	fields := m.Fields()
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var headers []kafka.Header
+	for _, name := range names {
+		headers = append(headers, kafka.Header{Key: name, Value: []byte(fields[name])})
+	}
+	for _, name := range m.HeaderNames() {
+		headers = append(headers, kafka.Header{Key: name, Value: []byte(m.Headers[name])})
+	}
+	return headers
+}
+
+// topicSettings возвращает настройки writer'а топика из текущей конфигурации каналов.
+func (kp *KafkaPublisher) topicSettings(topic string) (KafkaWriterConfig, error) {
+	settings, err := kafkaTopicSettings(kp.registry.GetAllChannels())
+	if err != nil {
+		return KafkaWriterConfig{}, err
+	}
+	return settings[topic], nil
+}
+
+// onChannelsChanged закрывает writers удаленных и перенастроенных топиков;
+// следующая публикация создаст writer заново.
+func (kp *KafkaPublisher) onChannelsChanged(channels map[string]EventChannelInfo) {
+	settings, err := kafkaTopicSettings(channels)
+	if err != nil {
+		log.Printf("kafka publisher: ignoring channel change: %v", err)
+		return
+	}
+	kp.writers.sync(settings)
+}
+
+// Close закрывает все writers, дождавшись начатых публикаций и сброса буферов async-writers.
+func (kp *KafkaPublisher) Close() error {
+	kp.writers.close()
 	return nil
 }
 
+// AsyncFailures возвращает число сообщений, которые async-writers не смогли доставить.
+func (kp *KafkaPublisher) AsyncFailures() int64 {
+	return kp.asyncFailures.Load()
+}
+
 // EnsureTopicsExist создает топики если их нет (опционально, для development)
 func (kp *KafkaPublisher) EnsureTopicsExist(topics []string) error {
 	conn, err := kafka.Dial("tcp", kp.brokers[0])
@@ -0,0 +1,270 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestKafkaPublisher_BuildMessageUsesPartitionKey(t *testing.T) {
+	registry := createTestRegistryFromConfig(t, `{
+		"OrderStatusEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order", "partition_key": "/order_id"}
+	}`)
+	publisher := NewKafkaPublisher([]string{"localhost:9092"}, registry)
+
+	msg, err := publisher.buildMessage(createOrderEvent("order-1", time.Now()))
+	assertNoError(t, err)
+	if string(msg.Key) != "12345" {
+		t.Errorf("expected key 12345, got %q", msg.Key)
+	}
+
+	event := createOrderEvent("order-2", time.Now())
+	delete(event.Payload, "order_id")
+	if _, err := publisher.buildMessage(event); !errors.Is(err, domain.ErrMissingPartitionKey) {
+		t.Errorf("expected ErrMissingPartitionKey, got %v", err)
+	}
+}
+
+func TestKafkaPublisher_BuildMessageMapsMetadataToHeaders(t *testing.T) {
+	publisher := NewKafkaPublisher([]string{"localhost:9092"}, createTestRegistry(t))
+	event := createOrderEvent("order-1", time.Now())
+	event.Metadata = &domain.EventMetadata{
+		CorrelationID: "corr-1",
+		Source:        "checkout",
+		Tenant:        "acme",
+		Headers:       map[string]string{"request-origin": "web"},
+	}
+
+	msg, err := publisher.buildMessage(event)
+	assertNoError(t, err)
+
+	headers := make(map[string]string)
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	expected := map[string]string{
+		"event-type":     "OrderStatusEvent",
+		"correlation-id": "corr-1",
+		"source":         "checkout",
+		"tenant":         "acme",
+		"request-origin": "web",
+	}
+	for key, want := range expected {
+		if headers[key] != want {
+			t.Errorf("header %s: expected %q, got %q", key, want, headers[key])
+		}
+	}
+	if _, ok := headers["causation-id"]; ok {
+		t.Error("empty metadata fields must not become headers")
+	}
+
+	decoded, err := decodeEventMessage(msg, nil)
+	assertNoError(t, err)
+	if decoded.Metadata == nil || decoded.Metadata.CorrelationID != "corr-1" || decoded.Metadata.Headers["request-origin"] != "web" {
+		t.Errorf("expected metadata to survive the round trip, got %+v", decoded.Metadata)
+	}
+}
+
+func TestKafkaPublisher_BuildMessageKeepsEventFieldNames(t *testing.T) {
+	publisher := NewKafkaPublisher([]string{"localhost:9092"}, createTestRegistry(t))
+	event := createOrderEvent("order-1", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	msg, err := publisher.buildMessage(event)
+	assertNoError(t, err)
+
+	var value map[string]interface{}
+	assertNoError(t, json.Unmarshal(msg.Value, &value))
+	for _, key := range []string{"ID", "Type", "Timestamp", "Payload"} {
+		if _, ok := value[key]; !ok {
+			t.Errorf("expected message value key %q, got %v", key, value)
+		}
+	}
+	if _, ok := value["SchemaVersion"]; ok {
+		t.Errorf("expected default schema version to be omitted, got %v", value)
+	}
+}
+
+func TestKafkaPublisher_BuildMessageCloudEventsBinding(t *testing.T) {
+	for _, mode := range []CloudEventsMode{CloudEventsBinary, CloudEventsStructured} {
+		t.Run(string(mode), func(t *testing.T) {
+			registry := createTestRegistryFromConfig(t, fmt.Sprintf(
+				`{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order", "partition_key": "/order_id", "cloudevents": %q}}`, mode))
+			publisher := NewKafkaPublisher([]string{"localhost:9092"}, registry)
+			event := createOrderEvent("order-1", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+			event.SchemaVersion = 2
+			event.Metadata = &domain.EventMetadata{Source: "checkout", Tenant: "acme", Headers: map[string]string{"traceparent": "00-abc"}}
+
+			msg, err := publisher.buildMessage(event)
+			assertNoError(t, err)
+			if string(msg.Key) != "12345" {
+				t.Errorf("expected partition key to be kept, got %q", msg.Key)
+			}
+
+			headers := make(map[string]string)
+			for _, h := range msg.Headers {
+				headers[h.Key] = string(h.Value)
+			}
+			var value map[string]interface{}
+			assertNoError(t, json.Unmarshal(msg.Value, &value))
+			if mode == CloudEventsBinary {
+				if headers["ce_specversion"] != "1.0" || headers["ce_id"] != "order-1" || headers["ce_source"] != "checkout" ||
+					headers["ce_tenant"] != "acme" || headers["content-type"] != "application/json" {
+					t.Errorf("unexpected binary headers: %v", headers)
+				}
+				if value["order_id"] != "12345" {
+					t.Errorf("expected payload as message value, got %v", value)
+				}
+			} else {
+				if headers["content-type"] != CloudEventsContentType {
+					t.Errorf("unexpected structured headers: %v", headers)
+				}
+				if value["specversion"] != "1.0" || value["type"] != "OrderStatusEvent" || value["time"] != "2024-05-01T12:00:00Z" {
+					t.Errorf("unexpected structured value: %v", value)
+				}
+			}
+
+			decoded, err := decodeEventMessage(msg, nil)
+			assertNoError(t, err)
+			if decoded.ID != event.ID || decoded.Type != event.Type || !decoded.Timestamp.Equal(event.Timestamp) ||
+				decoded.SchemaVersion != 2 || decoded.Payload["status"] != "packed" {
+				t.Errorf("event did not survive the round trip: %+v", decoded)
+			}
+			if decoded.Metadata.Tenant != "acme" || decoded.Metadata.Headers["traceparent"] != "00-abc" {
+				t.Errorf("metadata did not survive the round trip: %+v", decoded.Metadata)
+			}
+		})
+	}
+}
+
+func TestKafkaWriterConfig_NewWriter(t *testing.T) {
+	cfg := KafkaWriterConfig{BatchSize: 500, LingerMs: 50, RequiredAcks: "all", Compression: "zstd", MaxMessageBytes: 2048, Async: true}
+	writer := cfg.newWriter([]string{"localhost:9092"}, "orders-topic", nil)
+	if writer.BatchSize != 500 || writer.BatchTimeout != 50*time.Millisecond || writer.RequiredAcks != kafka.RequireAll ||
+		writer.Compression != kafka.Zstd || writer.BatchBytes != 2048 || !writer.Async {
+		t.Errorf("writer does not match settings: %+v", writer)
+	}
+
+	defaults := KafkaWriterConfig{}.newWriter([]string{"localhost:9092"}, "orders-topic", nil)
+	if defaults.BatchSize != 100 || defaults.RequiredAcks != kafka.RequireOne || defaults.Async {
+		t.Errorf("expected default writer settings, got %+v", defaults)
+	}
+}
+
+func TestKafkaPublisher_RecreatesWritersOnReload(t *testing.T) {
+	registry := createTestRegistryFromConfig(t, `{
+		"OrderStatusEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order", "kafka": {"batch_size": 500}},
+		"PaymentEvent": {"type": "kafka", "endpoint": "payments-topic", "schema": "payment"}
+	}`)
+	publisher, writers := setupFakeKafkaPublisher(registry)
+	defer publisher.Close()
+
+	payment := createOrderEvent("payment-1", time.Now())
+	payment.Type = "PaymentEvent"
+	assertNoError(t, publisher.Publish(context.Background(), createOrderEvent("order-1", time.Now())))
+	assertNoError(t, publisher.Publish(context.Background(), payment))
+	orders := writers.get("orders-topic", 0)
+	if orders.cfg.BatchSize != 500 || len(orders.messages()) != 1 {
+		t.Fatalf("expected orders writer with channel settings, got %+v", orders.cfg)
+	}
+
+	// Reload: orders topic gets new settings, payments channel is removed
+	channels := registry.GetAllChannels()
+	info := channels["OrderStatusEvent"]
+	info.Kafka = &KafkaWriterConfig{BatchSize: 10}
+	channels["OrderStatusEvent"] = info
+	delete(channels, "PaymentEvent")
+	registry.Apply(channels)
+	publisher.writers.closing.Wait()
+
+	if !orders.isClosed() || !writers.get("payments-topic", 0).isClosed() {
+		t.Error("expected old writers to be closed")
+	}
+	assertNoError(t, publisher.Publish(context.Background(), createOrderEvent("order-2", time.Now())))
+	if recreated := writers.get("orders-topic", 1); recreated == nil || recreated.cfg.BatchSize != 10 {
+		t.Errorf("expected recreated orders writer with batch size 10, got %+v", recreated)
+	}
+}
+
+func TestKafkaPublisher_ClassifiesKafkaErrors(t *testing.T) {
+	registry := createTestRegistryFromConfig(t, `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order"}}`)
+	cases := []struct {
+		name     string
+		err      error
+		rejected bool
+	}{
+		{"message too large", kafka.MessageSizeTooLarge, true},
+		{"topic authorization failed", kafka.TopicAuthorizationFailed, true},
+		{"leader not available", kafka.LeaderNotAvailable, false},
+		{"network error", errors.New("dial tcp: connection refused"), false},
+		{"write errors, all rejected", kafka.WriteErrors{kafka.MessageSizeTooLarge}, true},
+		{"write errors, partly temporary", kafka.WriteErrors{kafka.MessageSizeTooLarge, kafka.NotLeaderForPartition}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			publisher, writers := setupFakeKafkaPublisher(registry)
+			defer publisher.Close()
+			writers.err = tc.err
+
+			err := publisher.Publish(context.Background(), createOrderEvent("order-1", time.Now()))
+			if err == nil {
+				t.Fatal("expected publish error")
+			}
+			if errors.Is(err, domain.ErrPublishRejected) != tc.rejected {
+				t.Errorf("expected rejected=%v, got %v", tc.rejected, err)
+			}
+		})
+	}
+}
+
+func TestKafkaPublisher_BatchClassifiesPerMessage(t *testing.T) {
+	registry := createTestRegistryFromConfig(t, `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order"}}`)
+	publisher, writers := setupFakeKafkaPublisher(registry)
+	defer publisher.Close()
+	writers.err = kafka.WriteErrors{nil, kafka.MessageSizeTooLarge, kafka.RequestTimedOut}
+
+	errs := publisher.PublishBatchTo(context.Background(), "orders-topic", []*domain.Event{
+		createOrderEvent("order-1", time.Now()),
+		createOrderEvent("order-2", time.Now()),
+		createOrderEvent("order-3", time.Now()),
+	})
+
+	if errs[0] != nil || !errors.Is(errs[1], domain.ErrPublishRejected) || errs[2] == nil || errors.Is(errs[2], domain.ErrPublishRejected) {
+		t.Errorf("expected only order-2 rejected and order-3 temporary, got %v", errs)
+	}
+}
+
+func TestKafkaWriterConfig_Validation(t *testing.T) {
+	configs := map[string]string{
+		"unknown acks":        `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders", "schema": "order", "kafka": {"required_acks": "two"}}}`,
+		"unknown compression": `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders", "schema": "order", "kafka": {"compression": "brotli"}}}`,
+		"conflicting topic": `{
+			"OrderStatusEvent": {"type": "kafka", "endpoint": "orders", "schema": "order", "kafka": {"batch_size": 10}},
+			"OrderCreatedEvent": {"type": "kafka", "endpoint": "orders", "schema": "order", "kafka": {"batch_size": 20}}}`,
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "channels.json")
+			assertNoError(t, os.WriteFile(path, []byte(config), 0644))
+			if _, err := NewEventRegistryFromFile(path); err == nil {
+				t.Error("expected config to be rejected")
+			}
+		})
+	}
+}
+
+// === Test Helpers ===
+
+func setupFakeKafkaPublisher(registry *EventRegistry) (*KafkaPublisher, *fakeWriterFactory) {
+	factory := &fakeWriterFactory{created: make(map[string][]*fakeWriter)}
+	publisher := NewKafkaPublisher([]string{"localhost:9092"}, registry)
+	publisher.writers = newWriterPool(factory.newWriter)
+	return publisher, factory
+}
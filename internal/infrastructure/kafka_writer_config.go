@@ -0,0 +1,118 @@
+package infrastructure
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaWriterConfig — настройки producer'а для kafka-destinations канала.
+// Пустые поля получают значения по умолчанию.
+type KafkaWriterConfig struct {
+	BatchSize       int    `json:"batch_size,omitempty"`        // сообщений в batch (по умолчанию 100)
+	LingerMs        int    `json:"linger_ms,omitempty"`         // сколько ждать неполный batch (по умолчанию 10)
+	RequiredAcks    string `json:"required_acks,omitempty"`     // none, one, all (по умолчанию one)
+	Compression     string `json:"compression,omitempty"`       // none, gzip, snappy, lz4, zstd
+	MaxMessageBytes int64  `json:"max_message_bytes,omitempty"` // предел размера запроса (по умолчанию 1 MiB)
+	Async           bool   `json:"async,omitempty"`             // не ждать подтверждения брокера; ошибки только логируются
+}
+
+var kafkaRequiredAcks = map[string]kafka.RequiredAcks{
+	"":     kafka.RequireOne,
+	"none": kafka.RequireNone,
+	"one":  kafka.RequireOne,
+	"all":  kafka.RequireAll,
+}
+
+var kafkaCompressionCodecs = map[string]kafka.Compression{
+	"":       0,
+	"none":   0,
+	"gzip":   kafka.Gzip,
+	"snappy": kafka.Snappy,
+	"lz4":    kafka.Lz4,
+	"zstd":   kafka.Zstd,
+}
+
+func (c *KafkaWriterConfig) validate() error {
+	if c == nil {
+		return nil
+	}
+	if c.BatchSize < 0 || c.LingerMs < 0 || c.MaxMessageBytes < 0 {
+		return fmt.Errorf("kafka batch_size, linger_ms and max_message_bytes must not be negative")
+	}
+	if _, ok := kafkaRequiredAcks[c.RequiredAcks]; !ok {
+		return fmt.Errorf("unknown kafka required_acks %q (want none, one or all)", c.RequiredAcks)
+	}
+	if _, ok := kafkaCompressionCodecs[c.Compression]; !ok {
+		return fmt.Errorf("unknown kafka compression %q", c.Compression)
+	}
+	return nil
+}
+
+// newWriter создает writer для топика с этими настройками. В async-режиме
+// onAsyncError получает число сообщений, которые не удалось доставить.
+func (c KafkaWriterConfig) newWriter(brokers []string, topic string, onAsyncError func(n int)) *kafka.Writer {
+	batchSize := c.BatchSize
+	if batchSize == 0 {
+		batchSize = 100
+	}
+	linger := 10 * time.Millisecond
+	if c.LingerMs > 0 {
+		linger = time.Duration(c.LingerMs) * time.Millisecond
+	}
+	maxBytes := c.MaxMessageBytes
+	if maxBytes == 0 {
+		maxBytes = 1 << 20
+	}
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{}, // Одинаковый ключ — одна партиция; без ключа — round-robin
+		BatchSize:    batchSize,
+		BatchTimeout: linger,
+		BatchBytes:   maxBytes,
+		RequiredAcks: kafkaRequiredAcks[c.RequiredAcks],
+		Compression:  kafkaCompressionCodecs[c.Compression],
+		Async:        c.Async,
+	}
+	if c.Async {
+		writer.Completion = func(messages []kafka.Message, err error) {
+			if err != nil {
+				log.Printf("kafka async publish error to topic %s (%d messages): %v", topic, len(messages), err)
+				if onAsyncError != nil {
+					onAsyncError(len(messages))
+				}
+			}
+		}
+	}
+	return writer
+}
+
+// kafkaTopicSettings собирает настройки writer'ов по топикам из всех kafka-destinations.
+// Каналы, пишущие в один топик, должны задавать одинаковые настройки.
+func kafkaTopicSettings(channels map[string]EventChannelInfo) (map[string]KafkaWriterConfig, error) {
+	settings := make(map[string]KafkaWriterConfig)
+	owners := make(map[string]string)
+	for _, name := range sortedChannelNames(channels) {
+		info := channels[name]
+		var cfg KafkaWriterConfig
+		if info.Kafka != nil {
+			cfg = *info.Kafka
+		}
+		for _, d := range info.Targets() {
+			if d.Type != "kafka" {
+				continue
+			}
+			if existing, ok := settings[d.Endpoint]; ok && !reflect.DeepEqual(existing, cfg) {
+				return nil, fmt.Errorf("channels %q and %q write to topic %s with different kafka settings", owners[d.Endpoint], name, d.Endpoint)
+			}
+			settings[d.Endpoint] = cfg
+			owners[d.Endpoint] = name
+		}
+	}
+	return settings, nil
+}
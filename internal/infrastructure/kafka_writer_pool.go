@@ -0,0 +1,124 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// errWriterPoolClosed возвращается при публикации после KafkaPublisher.Close.
+var errWriterPoolClosed = errors.New("kafka writer pool is closed")
+
+// messageWriter — подмножество *kafka.Writer, которое нужно publisher'у.
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// writerPool хранит по одному writer на топик. Writer создается лениво при первой
+// публикации; writer удаленного или перенастроенного топика закрывается после
+// завершения начатых через него записей.
+type writerPool struct {
+	newWriter func(topic string, cfg KafkaWriterConfig) messageWriter
+
+	mu      sync.Mutex
+	writers map[string]*pooledWriter
+	closed  bool
+	closing sync.WaitGroup // writers, которые закрываются в фоне
+}
+
+type pooledWriter struct {
+	writer   messageWriter
+	cfg      KafkaWriterConfig
+	inflight sync.WaitGroup
+}
+
+func newWriterPool(newWriter func(topic string, cfg KafkaWriterConfig) messageWriter) *writerPool {
+	return &writerPool{
+		newWriter: newWriter,
+		writers:   make(map[string]*pooledWriter),
+	}
+}
+
+// acquire возвращает writer топика, создавая его с настройками из config, если его еще нет.
+// Вызывающий обязан вызвать release после записи.
+func (p *writerPool) acquire(topic string, config func() (KafkaWriterConfig, error)) (messageWriter, func(), error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, nil, errWriterPoolClosed
+	}
+	pw, ok := p.writers[topic]
+	if !ok {
+		cfg, err := config()
+		if err != nil {
+			return nil, nil, err
+		}
+		pw = &pooledWriter{writer: p.newWriter(topic, cfg), cfg: cfg}
+		p.writers[topic] = pw
+		log.Printf("📝 Created new Kafka writer for topic: %s", topic)
+	}
+	pw.inflight.Add(1)
+	return pw.writer, pw.inflight.Done, nil
+}
+
+// sync закрывает writers топиков, которых больше нет в settings или чьи настройки изменились.
+func (p *writerPool) sync(settings map[string]KafkaWriterConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for topic, pw := range p.writers {
+		cfg, ok := settings[topic]
+		switch {
+		case !ok:
+			log.Printf("🗑 Kafka topic %s removed from channels, closing writer", topic)
+		case !reflect.DeepEqual(cfg, pw.cfg):
+			log.Printf("🔁 Kafka writer settings for topic %s changed, recreating writer", topic)
+		default:
+			continue
+		}
+		p.retire(topic, pw)
+	}
+}
+
+// retire убирает writer из пула и закрывает его в фоне, дождавшись начатых записей.
+// Вызывается под p.mu.
+func (p *writerPool) retire(topic string, pw *pooledWriter) {
+	delete(p.writers, topic)
+	p.closing.Add(1)
+	go func() {
+		defer p.closing.Done()
+		pw.inflight.Wait()
+		if err := pw.writer.Close(); err != nil {
+			log.Printf("error closing writer for topic %s: %v", topic, err)
+		}
+	}()
+}
+
+// topics возвращает топики, для которых есть открытый writer.
+func (p *writerPool) topics() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	topics := make([]string, 0, len(p.writers))
+	for topic := range p.writers {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// close закрывает все writers и ждет завершения записей и фоновых закрытий.
+func (p *writerPool) close() {
+	p.mu.Lock()
+	p.closed = true
+	for topic, pw := range p.writers {
+		p.retire(topic, pw)
+	}
+	p.mu.Unlock()
+	p.closing.Wait()
+}
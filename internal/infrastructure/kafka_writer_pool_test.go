@@ -0,0 +1,174 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestWriterPool_CreatesOneWriterPerTopicConcurrently(t *testing.T) {
+	factory := &fakeWriterFactory{created: make(map[string][]*fakeWriter)}
+	pool := newWriterPool(factory.newWriter)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			topic := []string{"orders", "payments"}[i%2]
+			writer, release, err := pool.acquire(topic, func() (KafkaWriterConfig, error) { return KafkaWriterConfig{}, nil })
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+			writer.WriteMessages(context.Background(), kafka.Message{Value: []byte("x")})
+		}(i)
+	}
+	wg.Wait()
+
+	if n := factory.count("orders"); n != 1 {
+		t.Errorf("expected exactly one orders writer, got %d", n)
+	}
+	if n := factory.count("payments"); n != 1 {
+		t.Errorf("expected exactly one payments writer, got %d", n)
+	}
+	if got := len(factory.get("orders", 0).messages()) + len(factory.get("payments", 0).messages()); got != 50 {
+		t.Errorf("expected 50 messages, got %d", got)
+	}
+}
+
+func TestWriterPool_ClosesRemovedTopicAfterInflightWrites(t *testing.T) {
+	factory := &fakeWriterFactory{created: make(map[string][]*fakeWriter)}
+	pool := newWriterPool(factory.newWriter)
+	noConfig := func() (KafkaWriterConfig, error) { return KafkaWriterConfig{}, nil }
+
+	_, release, err := pool.acquire("orders", noConfig)
+	assertNoError(t, err)
+	_, releaseKept, err := pool.acquire("payments", noConfig)
+	assertNoError(t, err)
+	releaseKept()
+
+	pool.sync(map[string]KafkaWriterConfig{"payments": {}})
+	if topics := pool.topics(); len(topics) != 1 || topics[0] != "payments" {
+		t.Fatalf("expected only payments writer to remain, got %v", topics)
+	}
+
+	// The removed writer is still in use and must not be closed yet
+	time.Sleep(20 * time.Millisecond)
+	orders := factory.get("orders", 0)
+	if orders.isClosed() {
+		t.Fatal("writer closed while a write was in flight")
+	}
+	release()
+	pool.closing.Wait()
+	if !orders.isClosed() {
+		t.Error("expected removed writer to be closed after release")
+	}
+	if factory.get("payments", 0).isClosed() {
+		t.Error("expected kept writer to stay open")
+	}
+}
+
+func TestWriterPool_CloseRejectsNewWriters(t *testing.T) {
+	factory := &fakeWriterFactory{created: make(map[string][]*fakeWriter)}
+	pool := newWriterPool(factory.newWriter)
+	noConfig := func() (KafkaWriterConfig, error) { return KafkaWriterConfig{}, nil }
+
+	_, release, err := pool.acquire("orders", noConfig)
+	assertNoError(t, err)
+	release()
+
+	pool.close()
+	if !factory.get("orders", 0).isClosed() {
+		t.Error("expected writer to be closed")
+	}
+	if _, _, err := pool.acquire("orders", noConfig); !errors.Is(err, errWriterPoolClosed) {
+		t.Errorf("expected errWriterPoolClosed, got %v", err)
+	}
+}
+
+// === Test Helpers ===
+
+type fakeWriter struct {
+	cfg KafkaWriterConfig
+	err error // returned by every WriteMessages call
+
+	mu     sync.Mutex
+	msgs   []kafka.Message
+	writes int
+	closed bool
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errors.New("write to closed writer")
+	}
+	if w.err != nil {
+		return w.err
+	}
+	w.writes++
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+func (w *fakeWriter) messages() []kafka.Message {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]kafka.Message(nil), w.msgs...)
+}
+
+func (w *fakeWriter) writeCalls() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.writes
+}
+
+func (w *fakeWriter) isClosed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+type fakeWriterFactory struct {
+	mu      sync.Mutex
+	created map[string][]*fakeWriter
+	err     error // error for the writers created from now on
+}
+
+func (f *fakeWriterFactory) newWriter(topic string, cfg KafkaWriterConfig) messageWriter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeWriter{cfg: cfg, err: f.err}
+	f.created[topic] = append(f.created[topic], w)
+	return w
+}
+
+func (f *fakeWriterFactory) count(topic string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.created[topic])
+}
+
+// get returns the i-th writer created for the topic, or nil.
+func (f *fakeWriterFactory) get(topic string, i int) *fakeWriter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if i >= len(f.created[topic]) {
+		return nil
+	}
+	return f.created[topic][i]
+}
@@ -0,0 +1,49 @@
+package infrastructure
+
+import (
+	"context"
+	"event-system/internal/domain"
+	"fmt"
+	"sync"
+)
+
+// MemoryPublisher хранит события в памяти по endpoint канала.
+// Подходит для тестов и локальной разработки; данные теряются при рестарте.
+type MemoryPublisher struct {
+	registry *EventRegistry
+
+	mu     sync.RWMutex
+	events map[string][]*domain.Event
+}
+
+func NewMemoryPublisher(registry *EventRegistry) *MemoryPublisher {
+	return &MemoryPublisher{
+		registry: registry,
+		events:   make(map[string][]*domain.Event),
+	}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	endpoint, _, err := p.registry.ResolveChannel(event.Type)
+	if err != nil {
+		return fmt.Errorf("failed to resolve channel for event type %s: %w", event.Type, err)
+	}
+	return p.PublishTo(ctx, endpoint, event)
+}
+
+func (p *MemoryPublisher) PublishTo(ctx context.Context, endpoint string, event *domain.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events[endpoint] = append(p.events[endpoint], event)
+	return nil
+}
+
+// Events возвращает копию событий, опубликованных в endpoint.
+func (p *MemoryPublisher) Events(endpoint string) []*domain.Event {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]*domain.Event(nil), p.events[endpoint]...)
+}
@@ -0,0 +1,215 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"event-system/internal/domain"
+	"fmt"
+	"log"
+	"time"
+)
+
+const outboxSchema = `
+CREATE TABLE IF NOT EXISTS outbox (
+	event_id        TEXT    NOT NULL PRIMARY KEY,
+	channel         TEXT    NOT NULL,
+	attempts        INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at INTEGER NOT NULL DEFAULT 0,
+	last_error      TEXT    NOT NULL DEFAULT '',
+	delivered_at    INTEGER,
+	dead_at         INTEGER, -- доставка прекращена, событие передано в dead letters
+	FOREIGN KEY (event_id, channel) REFERENCES events (id, channel)
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox (delivered_at, next_attempt_at);
+`
+
+// OutboxEntry — событие, ожидающее доставки из outbox.
+type OutboxEntry struct {
+	Event    *domain.Event
+	Attempts int
+}
+
+// SQLiteOutbox реализует transactional outbox поверх SQLitePublisher:
+// Publish фиксирует событие в локальной базе, а OutboxRelay затем доставляет его брокеру.
+type SQLiteOutbox struct {
+	store *SQLitePublisher
+}
+
+func NewSQLiteOutbox(store *SQLitePublisher) (*SQLiteOutbox, error) {
+	if _, err := store.db.Exec(outboxSchema); err != nil {
+		return nil, fmt.Errorf("failed to create outbox schema: %w", err)
+	}
+	return &SQLiteOutbox{store: store}, nil
+}
+
+// Publish сохраняет событие и запись outbox в одной транзакции.
+func (o *SQLiteOutbox) Publish(ctx context.Context, event *domain.Event) error {
+	return o.PublishAll(ctx, []*domain.Event{event})
+}
+
+// PublishAll сохраняет события в одной транзакции: либо все, либо ни одного.
+func (o *SQLiteOutbox) PublishAll(ctx context.Context, events []*domain.Event) error {
+	tx, err := o.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin outbox transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	channels := make([]string, len(events))
+	for i, event := range events {
+		if channels[i], err = o.enqueue(ctx, tx, event); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit outbox transaction: %w", err)
+	}
+
+	for i, event := range events {
+		log.Printf("📥 Event %s queued in outbox (channel: %s)", event.Type, channels[i])
+	}
+	return nil
+}
+
+// PublishBatch сохраняет события в одной транзакции, но каждое под своим savepoint:
+// ошибка одного события (например, дубликат) не отменяет остальные.
+func (o *SQLiteOutbox) PublishBatch(ctx context.Context, events []*domain.Event) []error {
+	errs := make([]error, len(events))
+	fail := func(err error) []error {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+		return errs
+	}
+
+	tx, err := o.store.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fail(fmt.Errorf("failed to begin outbox transaction: %w", err))
+	}
+	defer tx.Rollback()
+
+	queued := 0
+	for i, event := range events {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT outbox_event`); err != nil {
+			return fail(fmt.Errorf("failed to create savepoint: %w", err))
+		}
+		if _, errs[i] = o.enqueue(ctx, tx, event); errs[i] != nil {
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO outbox_event`); err != nil {
+				return fail(fmt.Errorf("failed to roll back event %s: %w", event.ID, err))
+			}
+		} else {
+			queued++
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE outbox_event`); err != nil {
+			return fail(fmt.Errorf("failed to release savepoint: %w", err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		// Ни одно событие не сохранено, включая уже помеченные успешными
+		for i := range errs {
+			errs[i] = fmt.Errorf("failed to commit outbox transaction: %w", err)
+		}
+		return errs
+	}
+
+	log.Printf("📥 %d of %d events queued in outbox", queued, len(events))
+	return errs
+}
+
+// enqueue сохраняет событие и его запись outbox в рамках транзакции tx.
+func (o *SQLiteOutbox) enqueue(ctx context.Context, tx *sql.Tx, event *domain.Event) (string, error) {
+	channel, err := o.store.insertEvent(ctx, tx, event)
+	if err != nil {
+		return "", err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO outbox (event_id, channel) VALUES (?, ?)`, event.ID, channel); err != nil {
+		return "", fmt.Errorf("failed to enqueue event %s: %w", event.ID, err)
+	}
+	return channel, nil
+}
+
+// Pending возвращает недоставленные события, время повторной попытки которых наступило.
+// События, доставка которых прекращена (MarkDead), не возвращаются.
+func (o *SQLiteOutbox) Pending(now time.Time, limit int) ([]OutboxEntry, error) {
+	rows, err := o.store.db.Query(
+		`SELECT e.id, e.type, e.timestamp, e.schema_version, e.payload, e.metadata, o.attempts
+		 FROM outbox o JOIN events e ON e.id = o.event_id AND e.channel = o.channel
+		 WHERE o.delivered_at IS NULL AND o.dead_at IS NULL AND o.next_attempt_at <= ?
+		 ORDER BY e.created_at, e.id
+		 LIMIT ?`,
+		now.UnixNano(), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var result []OutboxEntry
+	for rows.Next() {
+		var (
+			event    domain.Event
+			ts       int64
+			payload  string
+			metadata string
+			attempts int
+		)
+		if err := rows.Scan(&event.ID, &event.Type, &ts, &event.SchemaVersion, &payload, &metadata, &attempts); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox entry: %w", err)
+		}
+		if err := json.Unmarshal([]byte(payload), &event.Payload); err != nil {
+			return nil, fmt.Errorf("failed to decode payload of event %s: %w", event.ID, err)
+		}
+		if event.Metadata, err = decodeMetadata(metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode metadata of event %s: %w", event.ID, err)
+		}
+		event.Timestamp = time.Unix(0, ts).UTC()
+		result = append(result, OutboxEntry{Event: &event, Attempts: attempts})
+	}
+	return result, rows.Err()
+}
+
+// PendingCount возвращает количество событий, ожидающих доставки.
+func (o *SQLiteOutbox) PendingCount() (int, error) {
+	var n int
+	err := o.store.db.QueryRow(`SELECT COUNT(*) FROM outbox WHERE delivered_at IS NULL AND dead_at IS NULL`).Scan(&n)
+	return n, err
+}
+
+// MarkDelivered помечает событие доставленным.
+func (o *SQLiteOutbox) MarkDelivered(eventID string, at time.Time) error {
+	_, err := o.store.db.Exec(
+		`UPDATE outbox SET delivered_at = ?, attempts = attempts + 1, last_error = '' WHERE event_id = ?`,
+		at.UnixNano(), eventID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark event %s delivered: %w", eventID, err)
+	}
+	return nil
+}
+
+// MarkFailed фиксирует неудачную попытку доставки и время следующей попытки.
+func (o *SQLiteOutbox) MarkFailed(eventID string, cause error, nextAttempt time.Time) error {
+	_, err := o.store.db.Exec(
+		`UPDATE outbox SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE event_id = ?`,
+		cause.Error(), nextAttempt.UnixNano(), eventID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record delivery failure for event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// MarkDead фиксирует последнюю неудачную попытку и прекращает доставку события.
+func (o *SQLiteOutbox) MarkDead(eventID string, cause error, at time.Time) error {
+	_, err := o.store.db.Exec(
+		`UPDATE outbox SET attempts = attempts + 1, last_error = ?, dead_at = ? WHERE event_id = ?`,
+		cause.Error(), at.UnixNano(), eventID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark event %s dead: %w", eventID, err)
+	}
+	return nil
+}
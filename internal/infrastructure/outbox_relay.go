@@ -0,0 +1,176 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"log"
+	"time"
+)
+
+type OutboxRelayConfig struct {
+	PollInterval time.Duration // как часто проверять outbox
+	BatchSize    int           // сколько событий забирать за один проход
+	BaseBackoff  time.Duration // задержка после первой неудачной попытки
+	MaxBackoff   time.Duration // верхняя граница экспоненциальной задержки
+	MaxAttempts  int           // после стольких неудачных попыток событие уходит в dead letters
+}
+
+// OutboxRelay в фоне переносит события из outbox в целевой publisher (обычно Kafka).
+// Доставка at-least-once: событие помечается доставленным только после успешного Publish,
+// а недоставленные записи переживают рестарт процесса. Событие, которое получатель
+// окончательно отклонил или которое не доставлено за MaxAttempts попыток, снимается
+// с доставки и передается в DeadLetters.
+type OutboxRelay struct {
+	DeadLetters domain.DeadLetterQueue // optional: куда складывать недоставляемые события
+
+	outbox *SQLiteOutbox
+	target domain.EventPublisher
+	cfg    OutboxRelayConfig
+	now    func() time.Time
+}
+
+func NewOutboxRelay(outbox *SQLiteOutbox, target domain.EventPublisher, cfg OutboxRelayConfig) *OutboxRelay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Minute
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 20
+	}
+	return &OutboxRelay{
+		outbox: outbox,
+		target: target,
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+// Run опрашивает outbox до отмены контекста.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.DrainOnce(ctx); err != nil {
+			log.Printf("outbox relay error: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DrainOnce выполняет один проход по outbox и возвращает количество доставленных событий.
+// Отмена ctx прерывает проход; недоставленные события остаются в outbox.
+func (r *OutboxRelay) DrainOnce(ctx context.Context) (int, error) {
+	entries, err := r.outbox.Pending(r.now(), r.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		// ErrDuplicateEvent — событие уже в целевом хранилище (повтор после сбоя до MarkDelivered), считаем доставленным
+		if err := r.target.Publish(ctx, entry.Event); err != nil && !onlyDuplicates(err) {
+			if permanentDeliveryFailure(err) || entry.Attempts+1 >= r.cfg.MaxAttempts {
+				if err := r.giveUp(ctx, entry, err); err != nil {
+					return delivered, err
+				}
+				continue
+			}
+			next := r.now().Add(r.backoff(entry.Attempts + 1))
+			log.Printf("outbox: delivery of event %s failed (attempt %d), retry at %s: %v",
+				entry.Event.ID, entry.Attempts+1, next.Format(time.RFC3339), err)
+			if err := r.outbox.MarkFailed(entry.Event.ID, err, next); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if err := r.outbox.MarkDelivered(entry.Event.ID, r.now()); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// giveUp передает событие в dead letters и прекращает его доставку. Если dead letter
+// не сохранен, запись остается в outbox и будет обработана следующим проходом.
+func (r *OutboxRelay) giveUp(ctx context.Context, entry OutboxEntry, cause error) error {
+	log.Printf("outbox: giving up on event %s after %d attempts: %v", entry.Event.ID, entry.Attempts+1, cause)
+	if r.DeadLetters != nil {
+		if err := r.DeadLetters.Add(context.WithoutCancel(ctx), entry.Event, domain.DeadLetterStagePublish, cause); err != nil {
+			return fmt.Errorf("failed to dead-letter event %s: %w", entry.Event.ID, err)
+		}
+	}
+	return r.outbox.MarkDead(entry.Event.ID, cause, r.now())
+}
+
+// Flush доставляет накопившиеся события, пока проходы по outbox что-то доставляют
+// или не истечет ctx, и возвращает число событий, оставшихся в outbox.
+// Вызывается при остановке после завершения Run.
+func (r *OutboxRelay) Flush(ctx context.Context) (int, error) {
+	for ctx.Err() == nil {
+		delivered, err := r.DrainOnce(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if delivered == 0 {
+			break
+		}
+	}
+	return r.outbox.PendingCount()
+}
+
+// backoff возвращает экспоненциальную задержку для попытки с номером attempt (начиная с 1).
+func (r *OutboxRelay) backoff(attempt int) time.Duration {
+	d := r.cfg.BaseBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= r.cfg.MaxBackoff {
+			return r.cfg.MaxBackoff
+		}
+	}
+	return d
+}
+
+// permanentDeliveryFailure сообщает, что повтор доставки не поможет: получатель отклонил
+// событие, событие не публикуется по текущей конфигурации канала или payload не кодируется
+// по схеме канала.
+func permanentDeliveryFailure(err error) bool {
+	var validationErr *domain.EventValidationError
+	return errors.Is(err, domain.ErrPublishRejected) ||
+		errors.Is(err, domain.ErrMissingPartitionKey) ||
+		errors.Is(err, domain.ErrUnknownEventType) ||
+		errors.Is(err, domain.ErrSchemaNotFound) ||
+		errors.As(err, &validationErr)
+}
+
+// onlyDuplicates сообщает, что все сбои доставки — повторы уже сохраненного события.
+func onlyDuplicates(err error) bool {
+	var deliveryErr *DeliveryError
+	if !errors.As(err, &deliveryErr) {
+		return errors.Is(err, ErrDuplicateEvent)
+	}
+	for _, r := range deliveryErr.Results {
+		if r.Err != nil && !errors.Is(r.Err, ErrDuplicateEvent) {
+			return false
+		}
+	}
+	return true
+}
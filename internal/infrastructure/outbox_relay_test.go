@@ -0,0 +1,245 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOutboxRelay_DeliversAndMarksDelivered(t *testing.T) {
+	outbox := setupOutbox(t, ":memory:")
+	target := &flakyPublisher{}
+	relay := NewOutboxRelay(outbox, target, OutboxRelayConfig{})
+
+	assertNoError(t, outbox.Publish(context.Background(), createOrderEvent("evt-1", time.Now().UTC())))
+
+	delivered, err := relay.DrainOnce(context.Background())
+	assertNoError(t, err)
+
+	if delivered != 1 || len(target.published) != 1 {
+		t.Fatalf("expected 1 delivered event, got %d (target saw %d)", delivered, len(target.published))
+	}
+	assertPendingCount(t, outbox, 0)
+}
+
+func TestOutboxRelay_RetriesWithBackoff(t *testing.T) {
+	outbox := setupOutbox(t, ":memory:")
+	target := &flakyPublisher{failures: 1}
+	relay := NewOutboxRelay(outbox, target, OutboxRelayConfig{BaseBackoff: time.Minute})
+
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	relay.now = func() time.Time { return now }
+
+	assertNoError(t, outbox.Publish(context.Background(), createOrderEvent("evt-1", now)))
+
+	delivered, err := relay.DrainOnce(context.Background())
+	assertNoError(t, err)
+	if delivered != 0 {
+		t.Fatalf("expected failed delivery, got %d delivered", delivered)
+	}
+
+	// Not retried until the backoff expires
+	now = now.Add(30 * time.Second)
+	delivered, _ = relay.DrainOnce(context.Background())
+	if delivered != 0 || target.calls != 1 {
+		t.Fatalf("expected no retry before backoff, got %d delivered after %d calls", delivered, target.calls)
+	}
+
+	now = now.Add(time.Minute)
+	delivered, err = relay.DrainOnce(context.Background())
+	assertNoError(t, err)
+	if delivered != 1 {
+		t.Fatalf("expected delivery after backoff, got %d", delivered)
+	}
+	assertPendingCount(t, outbox, 0)
+}
+
+func TestOutboxRelay_PendingSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.db")
+
+	first := setupOutbox(t, path)
+	assertNoError(t, first.Publish(context.Background(), createOrderEvent("evt-1", time.Now().UTC())))
+	assertNoError(t, first.store.Close())
+
+	second := setupOutbox(t, path)
+	assertPendingCount(t, second, 1)
+
+	target := &flakyPublisher{}
+	delivered, err := NewOutboxRelay(second, target, OutboxRelayConfig{}).DrainOnce(context.Background())
+	assertNoError(t, err)
+	if delivered != 1 || target.published[0].ID != "evt-1" {
+		t.Fatalf("expected evt-1 delivered after restart, got %d", delivered)
+	}
+}
+
+func TestOutboxRelay_FlushDrainsAllBatches(t *testing.T) {
+	outbox := setupOutbox(t, ":memory:")
+	target := &flakyPublisher{}
+	relay := NewOutboxRelay(outbox, target, OutboxRelayConfig{BatchSize: 2})
+
+	for _, id := range []string{"evt-1", "evt-2", "evt-3", "evt-4", "evt-5"} {
+		assertNoError(t, outbox.Publish(context.Background(), createOrderEvent(id, time.Now().UTC())))
+	}
+
+	remaining, err := relay.Flush(context.Background())
+	assertNoError(t, err)
+	if remaining != 0 || len(target.published) != 5 {
+		t.Fatalf("expected all 5 events flushed, got %d published and %d remaining", len(target.published), remaining)
+	}
+}
+
+func TestOutboxRelay_FlushReportsUndelivered(t *testing.T) {
+	outbox := setupOutbox(t, ":memory:")
+	target := &flakyPublisher{failures: 100}
+	relay := NewOutboxRelay(outbox, target, OutboxRelayConfig{})
+
+	assertNoError(t, outbox.Publish(context.Background(), createOrderEvent("evt-1", time.Now().UTC())))
+
+	remaining, err := relay.Flush(context.Background())
+	assertNoError(t, err)
+	if remaining != 1 || target.calls != 1 {
+		t.Fatalf("expected one attempt and 1 remaining event, got %d calls and %d remaining", target.calls, remaining)
+	}
+}
+
+func TestOutboxRelay_DeadLettersPermanentFailure(t *testing.T) {
+	outbox := setupOutbox(t, ":memory:")
+	target := &flakyPublisher{failures: 100, err: fmt.Errorf("webhook returned 400: %w", domain.ErrPublishRejected)}
+	deadLetters := &recordingDeadLetters{}
+	relay := NewOutboxRelay(outbox, target, OutboxRelayConfig{})
+	relay.DeadLetters = deadLetters
+
+	assertNoError(t, outbox.Publish(context.Background(), createOrderEvent("evt-1", time.Now().UTC())))
+
+	_, err := relay.DrainOnce(context.Background())
+	assertNoError(t, err)
+	if len(deadLetters.events) != 1 || deadLetters.events[0].ID != "evt-1" {
+		t.Fatalf("expected evt-1 dead-lettered on the first attempt, got %v", deadLetters.events)
+	}
+	assertPendingCount(t, outbox, 0)
+
+	// A dead row is never picked up again
+	relay.now = func() time.Time { return time.Now().Add(time.Hour) }
+	_, err = relay.DrainOnce(context.Background())
+	assertNoError(t, err)
+	if target.calls != 1 {
+		t.Errorf("expected no retry of a dead row, got %d calls", target.calls)
+	}
+}
+
+func TestOutboxRelay_DeadLettersAfterMaxAttempts(t *testing.T) {
+	outbox := setupOutbox(t, ":memory:")
+	target := &flakyPublisher{failures: 100}
+	deadLetters := &recordingDeadLetters{}
+	relay := NewOutboxRelay(outbox, target, OutboxRelayConfig{BaseBackoff: time.Minute, MaxAttempts: 3})
+	relay.DeadLetters = deadLetters
+
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	relay.now = func() time.Time { return now }
+	assertNoError(t, outbox.Publish(context.Background(), createOrderEvent("evt-1", now)))
+
+	for i := 0; i < 3; i++ {
+		_, err := relay.DrainOnce(context.Background())
+		assertNoError(t, err)
+		now = now.Add(time.Hour)
+	}
+
+	if target.calls != 3 || len(deadLetters.events) != 1 {
+		t.Fatalf("expected 3 attempts and one dead letter, got %d calls and %d dead letters", target.calls, len(deadLetters.events))
+	}
+	assertPendingCount(t, outbox, 0)
+}
+
+func TestSQLiteOutbox_PublishBatchIsolatesFailures(t *testing.T) {
+	outbox := setupOutbox(t, ":memory:")
+	assertNoError(t, outbox.Publish(context.Background(), createOrderEvent("order-1", time.Now())))
+
+	errs := outbox.PublishBatch(context.Background(), []*domain.Event{
+		createOrderEvent("order-1", time.Now()), // duplicate
+		createOrderEvent("order-2", time.Now()),
+	})
+
+	if !errors.Is(errs[0], ErrDuplicateEvent) || errs[1] != nil {
+		t.Fatalf("expected only the duplicate to fail, got %v", errs)
+	}
+	assertPendingCount(t, outbox, 2)
+}
+
+func TestSQLiteOutbox_PublishAllRollsBack(t *testing.T) {
+	outbox := setupOutbox(t, ":memory:")
+	assertNoError(t, outbox.Publish(context.Background(), createOrderEvent("order-1", time.Now())))
+
+	err := outbox.PublishAll(context.Background(), []*domain.Event{
+		createOrderEvent("order-2", time.Now()),
+		createOrderEvent("order-1", time.Now()), // duplicate
+	})
+
+	if !errors.Is(err, ErrDuplicateEvent) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+	assertPendingCount(t, outbox, 1)
+}
+
+func TestOutboxRelay_Backoff(t *testing.T) {
+	relay := NewOutboxRelay(nil, nil, OutboxRelayConfig{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, want := range expected {
+		if got := relay.backoff(i + 1); got != want {
+			t.Errorf("attempt %d: expected backoff %v, got %v", i+1, want, got)
+		}
+	}
+}
+
+// === Test Helpers ===
+
+func setupOutbox(t *testing.T, dsn string) *SQLiteOutbox {
+	store := setupSQLitePublisher(t, dsn)
+	outbox, err := NewSQLiteOutbox(store)
+	if err != nil {
+		t.Fatalf("failed to create outbox: %v", err)
+	}
+	return outbox
+}
+
+func assertPendingCount(t *testing.T, outbox *SQLiteOutbox, expected int) {
+	t.Helper()
+	n, err := outbox.PendingCount()
+	assertNoError(t, err)
+	if n != expected {
+		t.Fatalf("expected %d pending events, got %d", expected, n)
+	}
+}
+
+// flakyPublisher fails the first `failures` calls (with err, or a transient error) and succeeds afterwards.
+type flakyPublisher struct {
+	failures  int
+	err       error
+	calls     int
+	published []*domain.Event
+}
+
+func (f *flakyPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	f.calls++
+	if f.calls <= f.failures {
+		if f.err != nil {
+			return f.err
+		}
+		return errors.New("broker unavailable")
+	}
+	f.published = append(f.published, event)
+	return nil
+}
+
+type recordingDeadLetters struct {
+	events []*domain.Event
+}
+
+func (r *recordingDeadLetters) Add(ctx context.Context, event *domain.Event, stage domain.DeadLetterStage, cause error) error {
+	r.events = append(r.events, event)
+	return nil
+}
@@ -0,0 +1,17 @@
+package infrastructure
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validatePartitionKey проверяет синтаксис JSON pointer (RFC 6901).
+func validatePartitionKey(pointer string) error {
+	if pointer == "" {
+		return nil
+	}
+	if !strings.HasPrefix(pointer, "/") || pointer == "/" {
+		return fmt.Errorf("partition key %q must be a JSON pointer like /order_id", pointer)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package infrastructure
+
+import (
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// PayloadCodec кодирует payload в формат канала (Avro, Protobuf) и обратно;
+// реализуется domain.JSONSchemaValidator, который хранит схемы.
+type PayloadCodec interface {
+	EncodePayload(event *domain.Event) (domain.EncodedPayload, error)
+	DecodePayload(eventType string, schemaVersion int, data []byte) (map[string]interface{}, error)
+}
+
+// Значения заголовка content-type сообщений с закодированным payload.
+var payloadContentTypes = map[domain.PayloadFormat]string{
+	domain.PayloadFormatAvro:     "avro/binary",
+	domain.PayloadFormatProtobuf: "application/x-protobuf",
+}
+
+// encodedPayloadMessage заполняет сообщение Avro- или Protobuf-канала: value — только
+// payload, а ID, тип, timestamp и схема события переходят в заголовки.
+func (kp *KafkaPublisher) encodedPayloadMessage(msg *kafka.Message, event *domain.Event, format domain.PayloadFormat) error {
+	if kp.Payloads == nil {
+		return fmt.Errorf("no payload codec configured for %s channel %s", format, event.Type)
+	}
+	encoded, err := kp.Payloads.EncodePayload(event)
+	if err != nil {
+		return err
+	}
+
+	msg.Value = encoded.Data
+	msg.Headers = []kafka.Header{
+		{Key: "event-type", Value: []byte(event.Type)},
+		{Key: "schema-version", Value: []byte(strconv.Itoa(encoded.Version))},
+		{Key: "event-id", Value: []byte(event.ID)},
+		{Key: "event-timestamp", Value: []byte(event.Timestamp.UTC().Format(time.RFC3339Nano))},
+		{Key: "content-type", Value: []byte(payloadContentTypes[encoded.Format])},
+		{Key: "schema-id", Value: []byte(encoded.SchemaID)},
+	}
+	if encoded.Message != "" {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: "proto-message", Value: []byte(encoded.Message)})
+	}
+	msg.Headers = append(msg.Headers, metadataHeaders(event.Metadata)...)
+	return nil
+}
+
+// decodeEncodedPayloadMessage разбирает сообщение, записанное encodedPayloadMessage;
+// ok — content-type указывает на Avro или Protobuf.
+func decodeEncodedPayloadMessage(msg kafka.Message, payloads PayloadCodec) (*domain.Event, bool, error) {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	encoded := false
+	for _, contentType := range payloadContentTypes {
+		encoded = encoded || headers["content-type"] == contentType
+	}
+	if !encoded {
+		return nil, false, nil
+	}
+	if payloads == nil {
+		return nil, true, errors.New("no payload codec configured for encoded payload")
+	}
+
+	event := &domain.Event{ID: headers["event-id"], Type: headers["event-type"]}
+	if event.Type == "" {
+		return nil, true, errors.New("event type is missing")
+	}
+	version, err := strconv.Atoi(headers["schema-version"])
+	if err != nil {
+		return nil, true, fmt.Errorf("invalid schema-version header: %w", err)
+	}
+	event.SchemaVersion = version
+	if ts := headers["event-timestamp"]; ts != "" {
+		if event.Timestamp, err = time.Parse(time.RFC3339Nano, ts); err != nil {
+			return nil, true, fmt.Errorf("invalid event-timestamp header: %w", err)
+		}
+	}
+	if event.Payload, err = payloads.DecodePayload(event.Type, version, msg.Value); err != nil {
+		return nil, true, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	event.Metadata = metadataFromHeaders(msg.Headers)
+	return event, true, nil
+}
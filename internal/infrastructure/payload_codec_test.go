@@ -0,0 +1,69 @@
+package infrastructure
+
+import (
+	"event-system/internal/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKafkaPublisher_BuildMessageEncodesAvroPayload(t *testing.T) {
+	registry := createTestRegistryFromConfig(t, `{
+		"OrderStatusEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order", "format": "avro", "partition_key": "/order_id"}
+	}`)
+	schemaDir := t.TempDir()
+	assertNoError(t, os.WriteFile(filepath.Join(schemaDir, "order.avsc"), []byte(`{
+		"type": "record", "name": "Order",
+		"fields": [{"name": "order_id", "type": "string"}, {"name": "status", "type": "string"}, {"name": "user_id", "type": "string"}]
+	}`), 0644))
+	validator, err := domain.NewJSONSchemaValidator(schemaDir, registry)
+	assertNoError(t, err)
+
+	publisher := NewKafkaPublisher([]string{"localhost:9092"}, registry)
+	event := createOrderEvent("order-1", time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	event.Metadata = &domain.EventMetadata{Tenant: "acme"}
+	if _, err := publisher.buildMessage(event); err == nil {
+		t.Fatal("expected avro channel to need a payload codec")
+	}
+
+	publisher.Payloads = validator
+	msg, err := publisher.buildMessage(event)
+	assertNoError(t, err)
+
+	headers := make(map[string]string)
+	for _, h := range msg.Headers {
+		headers[h.Key] = string(h.Value)
+	}
+	if headers["content-type"] != "avro/binary" || headers["schema-id"] != "order.v1" || headers["schema-version"] != "1" ||
+		headers["event-id"] != "order-1" || headers["tenant"] != "acme" {
+		t.Errorf("unexpected headers: %v", headers)
+	}
+	if string(msg.Key) != "12345" || msg.Value[0] == '{' {
+		t.Errorf("expected partition key and binary value, got key %q value %q", msg.Key, msg.Value)
+	}
+
+	decoded, err := decodeEventMessage(msg, validator)
+	assertNoError(t, err)
+	if decoded.ID != event.ID || !decoded.Timestamp.Equal(event.Timestamp) || decoded.Payload["status"] != "packed" || decoded.Metadata.Tenant != "acme" {
+		t.Errorf("event did not survive the round trip: %+v", decoded)
+	}
+}
+
+func TestPayloadFormat_Validation(t *testing.T) {
+	configs := map[string]string{
+		"unknown format":           `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders", "schema": "order", "format": "xml"}}`,
+		"protobuf without message": `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders", "schema": "order", "format": "protobuf"}}`,
+		"message without protobuf": `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders", "schema": "order", "proto_message": "orders.Order"}}`,
+		"cloudevents with avro":    `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders", "schema": "order", "format": "avro", "cloudevents": "binary"}}`,
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "channels.json")
+			assertNoError(t, os.WriteFile(path, []byte(config), 0644))
+			if _, err := NewEventRegistryFromFile(path); err == nil {
+				t.Error("expected config to be rejected")
+			}
+		})
+	}
+}
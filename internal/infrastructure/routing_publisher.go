@@ -0,0 +1,271 @@
+package infrastructure
+
+import (
+	"context"
+	"event-system/internal/domain"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DestinationPublisher — publisher, который умеет доставлять событие в явно заданный endpoint.
+// Нужен для fan-out, когда у канала несколько destinations одного типа.
+type DestinationPublisher interface {
+	domain.EventPublisher
+	PublishTo(ctx context.Context, endpoint string, event *domain.Event) error
+}
+
+// BatchDestinationPublisher — backend, который пишет несколько событий в один endpoint за вызов.
+// Возвращает ошибку по каждому событию в том же порядке.
+type BatchDestinationPublisher interface {
+	PublishBatchTo(ctx context.Context, endpoint string, events []*domain.Event) []error
+}
+
+// DestinationResult — результат доставки события в одну destination.
+type DestinationResult struct {
+	Type     string `json:"type"`
+	Endpoint string `json:"endpoint"`
+	Primary  bool   `json:"primary,omitempty"`
+	Skipped  bool   `json:"skipped,omitempty"` // payload не прошел фильтр destination
+	Err      error  `json:"-"`
+}
+
+// DeliveryError возвращается, если доставка не удовлетворила политике канала.
+// Results содержит результат по каждой destination.
+type DeliveryError struct {
+	EventID string
+	Policy  DeliveryPolicy
+	Results []DestinationResult
+}
+
+func (e *DeliveryError) Error() string {
+	var failed []string
+	for _, r := range e.Results {
+		if r.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s:%s: %v", r.Type, r.Endpoint, r.Err))
+		}
+	}
+	return fmt.Sprintf("delivery of event %s failed (policy %s): %s", e.EventID, e.Policy, strings.Join(failed, "; "))
+}
+
+// Unwrap возвращает ошибки отдельных destinations для errors.Is / errors.As.
+func (e *DeliveryError) Unwrap() []error {
+	var errs []error
+	for _, r := range e.Results {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+	return errs
+}
+
+// RoutingPublisher выбирает publisher по полю type каждой destination канала
+// и доставляет событие во все destinations, чей фильтр подходит к payload.
+// Backends регистрируются один раз при старте.
+type RoutingPublisher struct {
+	registry *EventRegistry
+
+	mu       sync.RWMutex
+	backends map[string]DestinationPublisher
+}
+
+func NewRoutingPublisher(registry *EventRegistry) *RoutingPublisher {
+	return &RoutingPublisher{
+		registry: registry,
+		backends: make(map[string]DestinationPublisher),
+	}
+}
+
+// Register связывает тип канала с publisher. Повторная регистрация типа — ошибка.
+func (p *RoutingPublisher) Register(channelType string, publisher DestinationPublisher) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, exists := p.backends[channelType]; exists {
+		return fmt.Errorf("publisher for channel type %q already registered", channelType)
+	}
+	p.backends[channelType] = publisher
+	return nil
+}
+
+// Types возвращает зарегистрированные типы каналов.
+func (p *RoutingPublisher) Types() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	types := make([]string, 0, len(p.backends))
+	for t := range p.backends {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func (p *RoutingPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	_, err := p.Deliver(ctx, event)
+	return err
+}
+
+// Deliver публикует событие во все destinations канала параллельно и возвращает
+// результат по каждой. Ошибка возвращается, если не выполнена политика доставки канала;
+// сбои, допустимые политикой, только логируются.
+func (p *RoutingPublisher) Deliver(ctx context.Context, event *domain.Event) ([]DestinationResult, error) {
+	info, ok := p.registry.GetChannel(event.Type)
+	if !ok {
+		return nil, fmt.Errorf("%w: channel %q not found in event registry", domain.ErrUnknownEventType, event.Type)
+	}
+
+	targets := info.Targets()
+	results := make([]DestinationResult, len(targets))
+	var wg sync.WaitGroup
+	for i, d := range targets {
+		results[i] = DestinationResult{Type: d.Type, Endpoint: d.Endpoint, Primary: d.Primary}
+		if !d.Matches(event.Payload) {
+			results[i].Skipped = true
+			continue
+		}
+		wg.Add(1)
+		go func(i int, d ChannelDestination) {
+			defer wg.Done()
+			results[i].Err = p.publishTo(ctx, d, event)
+		}(i, d)
+	}
+	wg.Wait()
+
+	return results, settleDelivery(event, info.Policy(), results)
+}
+
+// PublishBatch доставляет пакет событий. Доставки группируются по destination:
+// backend с BatchDestinationPublisher получает группу одним вызовом (в порядке пакета),
+// остальным события передаются по одному. Политика проверяется для каждого события отдельно.
+func (p *RoutingPublisher) PublishBatch(ctx context.Context, events []*domain.Event) []error {
+	type delivery struct{ event, target int }
+	type destination struct{ channelType, endpoint string }
+
+	errs := make([]error, len(events))
+	policies := make([]DeliveryPolicy, len(events))
+	results := make([][]DestinationResult, len(events))
+	groups := make(map[destination][]delivery)
+	var order []destination
+	for i, event := range events {
+		info, ok := p.registry.GetChannel(event.Type)
+		if !ok {
+			errs[i] = fmt.Errorf("%w: channel %q not found in event registry", domain.ErrUnknownEventType, event.Type)
+			continue
+		}
+		policies[i] = info.Policy()
+		targets := info.Targets()
+		results[i] = make([]DestinationResult, len(targets))
+		for j, d := range targets {
+			results[i][j] = DestinationResult{Type: d.Type, Endpoint: d.Endpoint, Primary: d.Primary}
+			if !d.Matches(event.Payload) {
+				results[i][j].Skipped = true
+				continue
+			}
+			key := destination{d.Type, d.Endpoint}
+			if _, ok := groups[key]; !ok {
+				order = append(order, key)
+			}
+			groups[key] = append(groups[key], delivery{i, j})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, key := range order {
+		wg.Add(1)
+		go func(key destination, deliveries []delivery) {
+			defer wg.Done()
+			batch := make([]*domain.Event, len(deliveries))
+			for n, d := range deliveries {
+				batch[n] = events[d.event]
+			}
+			for n, err := range p.publishBatchTo(ctx, key.channelType, key.endpoint, batch) {
+				results[deliveries[n].event][deliveries[n].target].Err = err
+			}
+		}(key, groups[key])
+	}
+	wg.Wait()
+
+	for i, event := range events {
+		if errs[i] == nil {
+			errs[i] = settleDelivery(event, policies[i], results[i])
+		}
+	}
+	return errs
+}
+
+// settleDelivery сводит результаты по destinations в ошибку события согласно политике;
+// сбои, допустимые политикой, только логируются.
+func settleDelivery(event *domain.Event, policy DeliveryPolicy, results []DestinationResult) error {
+	// Канал с одной destination ведет себя как обычный publisher
+	if len(results) == 1 {
+		return results[0].Err
+	}
+
+	if !deliverySatisfied(policy, results) {
+		return &DeliveryError{EventID: event.ID, Policy: policy, Results: results}
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			log.Printf("⚠️ Event %s not delivered to %s:%s (tolerated by policy %s): %v", event.ID, r.Type, r.Endpoint, policy, r.Err)
+		}
+	}
+	return nil
+}
+
+func (p *RoutingPublisher) publishTo(ctx context.Context, d ChannelDestination, event *domain.Event) error {
+	p.mu.RLock()
+	backend, ok := p.backends[d.Type]
+	p.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no publisher registered for channel type %q (event type %s)", d.Type, event.Type)
+	}
+	return backend.PublishTo(ctx, d.Endpoint, event)
+}
+
+func (p *RoutingPublisher) publishBatchTo(ctx context.Context, channelType, endpoint string, events []*domain.Event) []error {
+	p.mu.RLock()
+	backend, ok := p.backends[channelType]
+	p.mu.RUnlock()
+
+	errs := make([]error, len(events))
+	if !ok {
+		for i, event := range events {
+			errs[i] = fmt.Errorf("no publisher registered for channel type %q (event type %s)", channelType, event.Type)
+		}
+		return errs
+	}
+	if batcher, ok := backend.(BatchDestinationPublisher); ok {
+		return batcher.PublishBatchTo(ctx, endpoint, events)
+	}
+	for i, event := range events {
+		errs[i] = backend.PublishTo(ctx, endpoint, event)
+	}
+	return errs
+}
+
+// deliverySatisfied проверяет результаты по политике. Destinations, пропущенные
+// фильтром, не учитываются.
+func deliverySatisfied(policy DeliveryPolicy, results []DestinationResult) bool {
+	attempted, delivered := 0, 0
+	for _, r := range results {
+		if r.Skipped {
+			continue
+		}
+		attempted++
+		if r.Err == nil {
+			delivered++
+		}
+		if policy == DeliveryPolicyPrimary && r.Primary && r.Err != nil {
+			return false
+		}
+	}
+	switch policy {
+	case DeliveryPolicyAny:
+		return attempted == 0 || delivered > 0
+	case DeliveryPolicyPrimary:
+		return true
+	default:
+		return delivered == attempted
+	}
+}
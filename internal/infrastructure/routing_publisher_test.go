@@ -0,0 +1,263 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoutingPublisher_RoutesByChannelType(t *testing.T) {
+	registry := createTestRegistryFromConfig(t, `{
+		"OrderStatusEvent": {"type": "memory", "endpoint": "orders", "schema": "order"},
+		"AuditEvent": {"type": "file", "endpoint": "audit/events.jsonl", "schema": "audit"}
+	}`)
+	dir := t.TempDir()
+
+	memory := NewMemoryPublisher(registry)
+	router := NewRoutingPublisher(registry)
+	assertNoError(t, router.Register("memory", memory))
+	assertNoError(t, router.Register("file", NewFilePublisher(registry, dir)))
+
+	assertNoError(t, router.Publish(context.Background(), createOrderEvent("order-1", time.Now())))
+	audit := createOrderEvent("audit-1", time.Now())
+	audit.Type = "AuditEvent"
+	assertNoError(t, router.Publish(context.Background(), audit))
+
+	if got := memory.Events("orders"); len(got) != 1 || got[0].ID != "order-1" {
+		t.Errorf("expected order event in memory publisher, got %+v", got)
+	}
+	lines := readJSONLines(t, filepath.Join(dir, "audit", "events.jsonl"))
+	if len(lines) != 1 || lines[0].ID != "audit-1" {
+		t.Errorf("expected audit event in file, got %+v", lines)
+	}
+}
+
+func TestRoutingPublisher_Errors(t *testing.T) {
+	registry := createTestRegistryFromConfig(t, `{
+		"OrderStatusEvent": {"type": "sqlite", "endpoint": "orders", "schema": "order"}
+	}`)
+	router := NewRoutingPublisher(registry)
+	assertNoError(t, router.Register("memory", NewMemoryPublisher(registry)))
+
+	if err := router.Register("memory", NewMemoryPublisher(registry)); err == nil {
+		t.Error("expected error for duplicate registration")
+	}
+	if err := router.Publish(context.Background(), createOrderEvent("order-1", time.Now())); err == nil {
+		t.Error("expected error for channel type without publisher")
+	}
+	unknown := createOrderEvent("order-2", time.Now())
+	unknown.Type = "UnknownEvent"
+	if err := router.Publish(context.Background(), unknown); err == nil {
+		t.Error("expected error for unknown event type")
+	}
+}
+
+func TestFilePublisher_RejectsEndpointOutsideBaseDir(t *testing.T) {
+	registry := createTestRegistryFromConfig(t, `{
+		"OrderStatusEvent": {"type": "file", "endpoint": "../../etc/orders.jsonl", "schema": "order"}
+	}`)
+	dir := t.TempDir()
+	publisher := NewFilePublisher(registry, filepath.Join(dir, "events"))
+
+	assertNoError(t, publisher.Publish(context.Background(), createOrderEvent("order-1", time.Now())))
+
+	// The endpoint is cleaned as an absolute path, so it stays inside baseDir
+	if _, err := os.Stat(filepath.Join(dir, "events", "etc", "orders.jsonl")); err != nil {
+		t.Errorf("expected file inside base dir: %v", err)
+	}
+}
+
+func TestRoutingPublisher_FanOutWithFilter(t *testing.T) {
+	registry := createTestRegistryFromConfig(t, `{
+		"OrderStatusEvent": {"schema": "order", "destinations": [
+			{"type": "memory", "endpoint": "orders"},
+			{"type": "memory", "endpoint": "analytics", "filter": {"status": ["shipped", "delivered"]}}
+		]}
+	}`)
+	memory := NewMemoryPublisher(registry)
+	router := NewRoutingPublisher(registry)
+	assertNoError(t, router.Register("memory", memory))
+
+	shipped := createOrderEvent("order-1", time.Now())
+	shipped.Payload["status"] = "shipped"
+	pending := createOrderEvent("order-2", time.Now())
+	pending.Payload["status"] = "pending"
+
+	results, err := router.Deliver(context.Background(), shipped)
+	assertNoError(t, err)
+	if len(results) != 2 || results[1].Skipped {
+		t.Errorf("expected delivery to both destinations, got %+v", results)
+	}
+	results, err = router.Deliver(context.Background(), pending)
+	assertNoError(t, err)
+	if !results[1].Skipped {
+		t.Errorf("expected analytics destination to be skipped by filter, got %+v", results)
+	}
+
+	if got := memory.Events("orders"); len(got) != 2 {
+		t.Errorf("expected 2 events in orders, got %d", len(got))
+	}
+	if got := memory.Events("analytics"); len(got) != 1 || got[0].ID != "order-1" {
+		t.Errorf("expected only the shipped order in analytics, got %+v", got)
+	}
+
+	// The first destination becomes primary and is what ResolveChannel returns
+	endpoint, _, err := registry.ResolveChannel("OrderStatusEvent")
+	assertNoError(t, err)
+	if endpoint != "orders" {
+		t.Errorf("expected primary endpoint orders, got %s", endpoint)
+	}
+}
+
+func TestRoutingPublisher_DeliveryPolicies(t *testing.T) {
+	cases := []struct {
+		policy      string
+		primaryFail bool
+		expectErr   bool
+	}{
+		{"all", false, true},
+		{"any", false, false},
+		{"any", true, false},
+		{"primary", false, false},
+		{"primary", true, true},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%s/primary-fails=%v", tc.policy, tc.primaryFail), func(t *testing.T) {
+			primary, secondary := "memory", "broken"
+			if tc.primaryFail {
+				primary, secondary = secondary, primary
+			}
+			registry := createTestRegistryFromConfig(t, fmt.Sprintf(`{
+				"OrderStatusEvent": {"schema": "order", "delivery_policy": %q, "destinations": [
+					{"type": %q, "endpoint": "orders", "primary": true},
+					{"type": %q, "endpoint": "analytics"}
+				]}
+			}`, tc.policy, primary, secondary))
+			router := NewRoutingPublisher(registry)
+			assertNoError(t, router.Register("memory", NewMemoryPublisher(registry)))
+			assertNoError(t, router.Register("broken", &failingDestination{}))
+
+			err := router.Publish(context.Background(), createOrderEvent("order-1", time.Now()))
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error=%v, got %v", tc.expectErr, err)
+			}
+			var deliveryErr *DeliveryError
+			if tc.expectErr && !errors.As(err, &deliveryErr) {
+				t.Fatalf("expected DeliveryError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestRoutingPublisher_PublishBatchWritesOncePerTopic(t *testing.T) {
+	registry := createTestRegistryFromConfig(t, `{
+		"OrderStatusEvent": {"schema": "order", "destinations": [
+			{"type": "kafka", "endpoint": "orders-topic"},
+			{"type": "memory", "endpoint": "orders-audit"}
+		]},
+		"PaymentEvent": {"type": "kafka", "endpoint": "payments-topic", "schema": "payment"}
+	}`)
+	kafkaPublisher, writers := setupFakeKafkaPublisher(registry)
+	defer kafkaPublisher.Close()
+	memory := NewMemoryPublisher(registry)
+	router := NewRoutingPublisher(registry)
+	assertNoError(t, router.Register("kafka", kafkaPublisher))
+	assertNoError(t, router.Register("memory", memory))
+
+	payment := createOrderEvent("payment-1", time.Now())
+	payment.Type = "PaymentEvent"
+	unknown := createOrderEvent("unknown-1", time.Now())
+	unknown.Type = "UnknownEvent"
+	events := []*domain.Event{
+		createOrderEvent("order-1", time.Now()),
+		payment,
+		unknown,
+		createOrderEvent("order-2", time.Now()),
+	}
+
+	errs := router.PublishBatch(context.Background(), events)
+
+	if errs[0] != nil || errs[1] != nil || errs[3] != nil {
+		t.Fatalf("expected known events to be delivered, got %v", errs)
+	}
+	if errs[2] == nil {
+		t.Error("expected error for unknown event type")
+	}
+	orders := writers.get("orders-topic", 0)
+	if orders.writeCalls() != 1 || len(orders.messages()) != 2 {
+		t.Errorf("expected both order events in one write, got %d writes with %d messages", orders.writeCalls(), len(orders.messages()))
+	}
+	if got := orders.messages(); len(got) == 2 && !strings.Contains(string(got[0].Value), `"order-1"`) {
+		t.Errorf("expected batch order to be preserved, got %s first", got[0].Value)
+	}
+	if got := memory.Events("orders-audit"); len(got) != 2 {
+		t.Errorf("expected fan-out of both order events to memory, got %d", len(got))
+	}
+	if writers.get("payments-topic", 0).writeCalls() != 1 {
+		t.Error("expected payment event in its own topic write")
+	}
+}
+
+func TestEventRegistry_RejectsInvalidDestinations(t *testing.T) {
+	configs := map[string]string{
+		"two primaries": `{"OrderStatusEvent": {"schema": "order", "destinations": [
+			{"type": "kafka", "endpoint": "a", "primary": true},
+			{"type": "kafka", "endpoint": "b", "primary": true}]}}`,
+		"missing endpoint": `{"OrderStatusEvent": {"schema": "order", "destinations": [{"type": "kafka"}]}}`,
+		"endpoint mismatch": `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders", "schema": "order", "destinations": [
+			{"type": "kafka", "endpoint": "other"}]}}`,
+		"unknown policy": `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders", "schema": "order", "delivery_policy": "most"}}`,
+		"webhook settings on kafka": `{"OrderStatusEvent": {"schema": "order", "destinations": [
+			{"type": "kafka", "endpoint": "orders", "webhook": {"timeout_ms": 100}}]}}`,
+		"negative destination webhook timeout": `{"OrderStatusEvent": {"schema": "order", "destinations": [
+			{"type": "webhook", "endpoint": "http://a", "webhook": {"timeout_ms": -1}}]}}`,
+		"duplicate webhook url": `{"OrderStatusEvent": {"schema": "order", "destinations": [
+			{"type": "webhook", "endpoint": "http://a"},
+			{"type": "webhook", "endpoint": "http://a", "webhook": {"secret": "s"}}]}}`,
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "channels.json")
+			assertNoError(t, os.WriteFile(path, []byte(config), 0644))
+			if _, err := NewEventRegistryFromFile(path); err == nil {
+				t.Error("expected config to be rejected")
+			}
+		})
+	}
+}
+
+// === Test Helpers ===
+
+func readJSONLines(t *testing.T, path string) []domain.Event {
+	t.Helper()
+	f, err := os.Open(path)
+	assertNoError(t, err)
+	defer f.Close()
+
+	var events []domain.Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event domain.EventJSON
+		assertNoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, *event.Event())
+	}
+	return events
+}
+
+type failingDestination struct{}
+
+func (f *failingDestination) Publish(ctx context.Context, event *domain.Event) error {
+	return errors.New("destination unavailable")
+}
+
+func (f *failingDestination) PublishTo(ctx context.Context, endpoint string, event *domain.Event) error {
+	return errors.New("destination unavailable")
+}
@@ -1,20 +1,211 @@
 package infrastructure
 
 import (
-    "event-system/internal/domain"
-    // Импортируй database/sql и sqlite3 драйвер
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"log"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
 )
 
+// ErrDuplicateEvent возвращается, если событие с таким ID уже сохранено в этот канал.
+var ErrDuplicateEvent = errors.New("event with this id already stored")
+
+// Одно событие может храниться в нескольких каналах (fan-out в несколько sqlite destinations),
+// поэтому ключ — ID события и канал.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS events (
+	id             TEXT    NOT NULL,
+	type           TEXT    NOT NULL,
+	timestamp      INTEGER NOT NULL,
+	schema_version INTEGER NOT NULL DEFAULT 0,
+	payload        TEXT    NOT NULL,
+	metadata       TEXT    NOT NULL DEFAULT '',
+	channel        TEXT    NOT NULL,
+	created_at     INTEGER NOT NULL,
+	PRIMARY KEY (id, channel)
+);
+CREATE INDEX IF NOT EXISTS idx_events_type ON events (type, timestamp);
+CREATE INDEX IF NOT EXISTS idx_events_timestamp ON events (timestamp);
+`
+
+// StoredEvent — событие, прочитанное из локального хранилища, вместе с каналом,
+// в который оно было адресовано.
+type StoredEvent struct {
+	Event    *domain.Event
+	Channel  string
+	StoredAt time.Time
+}
+
+// SQLitePublisher сохраняет события в локальную SQLite базу.
+// Используется для локальной разработки и edge-инсталляций без Kafka.
 type SQLitePublisher struct {
-    // conn *sql.DB
+	db       *sql.DB
+	registry *EventRegistry
+}
+
+// NewSQLitePublisher открывает (или создает) базу по dsn и создает схему при первом запуске.
+func NewSQLitePublisher(dsn string, registry *EventRegistry) (*SQLitePublisher, error) {
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database %s: %w", dsn, err)
+	}
+	// SQLite допускает только одного писателя; одно соединение избавляет от SQLITE_BUSY
+	// и позволяет работать с ":memory:" базой.
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create sqlite schema: %w", err)
+	}
+
+	return &SQLitePublisher{
+		db:       db,
+		registry: registry,
+	}, nil
+}
+
+func (p *SQLitePublisher) Publish(ctx context.Context, event *domain.Event) error {
+	channel, _, err := p.registry.ResolveChannel(event.Type)
+	if err != nil {
+		return fmt.Errorf("failed to resolve channel for event type %s: %w", event.Type, err)
+	}
+	return p.PublishTo(ctx, channel, event)
+}
+
+// PublishTo сохраняет событие с явно заданным каналом.
+func (p *SQLitePublisher) PublishTo(ctx context.Context, channel string, event *domain.Event) error {
+	if err := p.insertEventTo(ctx, p.db, channel, event); err != nil {
+		return err
+	}
+
+	log.Printf("💾 Event %s stored in sqlite (channel: %s)", event.Type, channel)
+	return nil
+}
+
+// sqlExecer — общий интерфейс *sql.DB и *sql.Tx.
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 }
 
-func NewSQLitePublisher(/* параметры */) *SQLitePublisher {
-    // инициализация
-    return &SQLitePublisher{}
+// insertEvent сохраняет событие и возвращает канал, в который оно адресовано.
+func (p *SQLitePublisher) insertEvent(ctx context.Context, db sqlExecer, event *domain.Event) (string, error) {
+	channel, _, err := p.registry.ResolveChannel(event.Type)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve channel for event type %s: %w", event.Type, err)
+	}
+	return channel, p.insertEventTo(ctx, db, channel, event)
 }
 
-func (p *SQLitePublisher) Publish(event *domain.Event) error {
-    // сохраняй event в БД
-    return nil
-}
\ No newline at end of file
+func (p *SQLitePublisher) insertEventTo(ctx context.Context, db sqlExecer, channel string, event *domain.Event) error {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+
+	metadata, err := encodeMetadata(event.Metadata)
+	if err != nil {
+		return err
+	}
+
+	res, err := db.ExecContext(ctx,
+		`INSERT INTO events (id, type, timestamp, schema_version, payload, metadata, channel, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (id, channel) DO NOTHING`,
+		event.ID, event.Type, event.Timestamp.UnixNano(), event.SchemaVersion, string(payload), metadata, channel, time.Now().UTC().UnixNano(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store event %s: %w", event.ID, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("event %s (channel %s): %w", event.ID, channel, ErrDuplicateEvent)
+	}
+	return nil
+}
+
+// FindByType возвращает события указанного типа в порядке их времени.
+func (p *SQLitePublisher) FindByType(eventType string) ([]StoredEvent, error) {
+	return p.query(
+		`SELECT id, type, timestamp, schema_version, payload, metadata, channel, created_at FROM events
+		 WHERE type = ? ORDER BY timestamp, id, channel`,
+		eventType,
+	)
+}
+
+// FindByTimeRange возвращает события с временем в полуинтервале [from, to).
+func (p *SQLitePublisher) FindByTimeRange(from, to time.Time) ([]StoredEvent, error) {
+	return p.query(
+		`SELECT id, type, timestamp, schema_version, payload, metadata, channel, created_at FROM events
+		 WHERE timestamp >= ? AND timestamp < ? ORDER BY timestamp, id, channel`,
+		from.UnixNano(), to.UnixNano(),
+	)
+}
+
+func (p *SQLitePublisher) query(query string, args ...any) ([]StoredEvent, error) {
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	var result []StoredEvent
+	for rows.Next() {
+		var (
+			event     domain.Event
+			ts        int64
+			payload   string
+			metadata  string
+			channel   string
+			createdAt int64
+		)
+		if err := rows.Scan(&event.ID, &event.Type, &ts, &event.SchemaVersion, &payload, &metadata, &channel, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		if err := json.Unmarshal([]byte(payload), &event.Payload); err != nil {
+			return nil, fmt.Errorf("failed to decode payload of event %s: %w", event.ID, err)
+		}
+		if event.Metadata, err = decodeMetadata(metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode metadata of event %s: %w", event.ID, err)
+		}
+		event.Timestamp = time.Unix(0, ts).UTC()
+		result = append(result, StoredEvent{
+			Event:    &event,
+			Channel:  channel,
+			StoredAt: time.Unix(0, createdAt).UTC(),
+		})
+	}
+	return result, rows.Err()
+}
+
+// encodeMetadata сериализует метаданные для колонки metadata; пустые — пустая строка.
+func encodeMetadata(m *domain.EventMetadata) (string, error) {
+	if m.IsEmpty() {
+		return "", nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal event metadata: %w", err)
+	}
+	return string(data), nil
+}
+
+func decodeMetadata(data string) (*domain.EventMetadata, error) {
+	if data == "" {
+		return nil, nil
+	}
+	var m domain.EventMetadata
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Close закрывает соединение с базой.
+func (p *SQLitePublisher) Close() error {
+	return p.db.Close()
+}
@@ -0,0 +1,168 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"event-system/internal/domain"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLitePublisher_PublishAndFindByType(t *testing.T) {
+	publisher := setupSQLitePublisher(t, filepath.Join(t.TempDir(), "events.db"))
+
+	event := createOrderEvent("evt-1", time.Now().UTC())
+	assertNoError(t, publisher.Publish(context.Background(), event))
+
+	stored, err := publisher.FindByType("OrderStatusEvent")
+	assertNoError(t, err)
+
+	if len(stored) != 1 {
+		t.Fatalf("expected 1 stored event, got %d", len(stored))
+	}
+	got := stored[0]
+	if got.Event.ID != event.ID {
+		t.Errorf("expected event ID '%s', got '%s'", event.ID, got.Event.ID)
+	}
+	if got.Channel != "orders-topic" {
+		t.Errorf("expected channel 'orders-topic', got '%s'", got.Channel)
+	}
+	if !got.Event.Timestamp.Equal(event.Timestamp) {
+		t.Errorf("expected timestamp %v, got %v", event.Timestamp, got.Event.Timestamp)
+	}
+	if got.Event.Payload["order_id"] != "12345" {
+		t.Errorf("expected order_id '12345', got '%v'", got.Event.Payload["order_id"])
+	}
+}
+
+func TestSQLitePublisher_DuplicateID(t *testing.T) {
+	publisher := setupSQLitePublisher(t, ":memory:")
+
+	event := createOrderEvent("evt-dup", time.Now().UTC())
+	assertNoError(t, publisher.Publish(context.Background(), event))
+
+	err := publisher.Publish(context.Background(), event)
+	if !errors.Is(err, ErrDuplicateEvent) {
+		t.Fatalf("expected ErrDuplicateEvent, got %v", err)
+	}
+}
+
+func TestSQLitePublisher_StoresEventPerChannel(t *testing.T) {
+	publisher := setupSQLitePublisher(t, ":memory:")
+
+	// Fan-out to two sqlite destinations stores the same event twice
+	event := createOrderEvent("evt-1", time.Now().UTC())
+	assertNoError(t, publisher.PublishTo(context.Background(), "orders", event))
+	assertNoError(t, publisher.PublishTo(context.Background(), "analytics", event))
+
+	stored, err := publisher.FindByType("OrderStatusEvent")
+	assertNoError(t, err)
+	if len(stored) != 2 || stored[0].Channel != "analytics" || stored[1].Channel != "orders" {
+		t.Fatalf("expected the event stored in both channels, got %+v", stored)
+	}
+	if err := publisher.PublishTo(context.Background(), "orders", event); !errors.Is(err, ErrDuplicateEvent) {
+		t.Errorf("expected ErrDuplicateEvent for a repeat in the same channel, got %v", err)
+	}
+}
+
+func TestSQLitePublisher_FindByTimeRange(t *testing.T) {
+	publisher := setupSQLitePublisher(t, ":memory:")
+
+	base := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"evt-a", "evt-b", "evt-c"} {
+		assertNoError(t, publisher.Publish(context.Background(), createOrderEvent(id, base.Add(time.Duration(i)*time.Hour))))
+	}
+
+	stored, err := publisher.FindByTimeRange(base.Add(30*time.Minute), base.Add(2*time.Hour))
+	assertNoError(t, err)
+
+	if len(stored) != 1 || stored[0].Event.ID != "evt-b" {
+		t.Fatalf("expected only evt-b in range, got %+v", stored)
+	}
+}
+
+func TestSQLitePublisher_StoresMetadata(t *testing.T) {
+	publisher := setupSQLitePublisher(t, ":memory:")
+
+	event := createOrderEvent("evt-1", time.Now().UTC())
+	event.Metadata = &domain.EventMetadata{CorrelationID: "corr-1", Actor: "user-42", Headers: map[string]string{"request-origin": "web"}}
+	assertNoError(t, publisher.Publish(context.Background(), event))
+	assertNoError(t, publisher.Publish(context.Background(), createOrderEvent("evt-2", time.Now().UTC())))
+
+	stored, err := publisher.FindByType("OrderStatusEvent")
+	assertNoError(t, err)
+	if len(stored) != 2 {
+		t.Fatalf("expected 2 stored events, got %d", len(stored))
+	}
+	got := stored[0].Event.Metadata
+	if got == nil || got.CorrelationID != "corr-1" || got.Actor != "user-42" || got.Headers["request-origin"] != "web" {
+		t.Errorf("expected metadata to be stored, got %+v", got)
+	}
+	if stored[1].Event.Metadata != nil {
+		t.Errorf("expected no metadata for plain event, got %+v", stored[1].Event.Metadata)
+	}
+}
+
+func TestSQLitePublisher_SchemaSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.db")
+	first := setupSQLitePublisher(t, path)
+	assertNoError(t, first.Publish(context.Background(), createOrderEvent("evt-1", time.Now().UTC())))
+	assertNoError(t, first.Close())
+
+	second := setupSQLitePublisher(t, path)
+	stored, err := second.FindByType("OrderStatusEvent")
+	assertNoError(t, err)
+	if len(stored) != 1 {
+		t.Fatalf("expected event to survive reopen, got %d events", len(stored))
+	}
+}
+
+// === Test Helpers ===
+
+func setupSQLitePublisher(t *testing.T, dsn string) *SQLitePublisher {
+	publisher, err := NewSQLitePublisher(dsn, createTestRegistry(t))
+	if err != nil {
+		t.Fatalf("failed to create sqlite publisher: %v", err)
+	}
+	t.Cleanup(func() { publisher.Close() })
+	return publisher
+}
+
+func createTestRegistry(t *testing.T) *EventRegistry {
+	return createTestRegistryFromConfig(t, `{"OrderStatusEvent": {"type": "kafka", "endpoint": "orders-topic", "schema": "order_status_notification"}}`)
+}
+
+func createTestRegistryFromConfig(t *testing.T, config string) *EventRegistry {
+	path := filepath.Join(t.TempDir(), "channels.json")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write channels config: %v", err)
+	}
+
+	registry, err := NewEventRegistryFromFile(path)
+	if err != nil {
+		t.Fatalf("failed to create test registry: %v", err)
+	}
+	return registry
+}
+
+func createOrderEvent(id string, ts time.Time) *domain.Event {
+	return &domain.Event{
+		ID:        id,
+		Type:      "OrderStatusEvent",
+		Timestamp: ts,
+		Payload: map[string]interface{}{
+			"order_id": "12345",
+			"status":   "packed",
+			"user_id":  "u42",
+		},
+	}
+}
+
+func assertNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
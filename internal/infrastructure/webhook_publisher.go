@@ -0,0 +1,217 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"event-system/internal/domain"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ErrPermanentDelivery — получатель отклонил событие (4xx, кроме 408 и 429); повтор не поможет.
+var ErrPermanentDelivery = domain.ErrPublishRejected
+
+const (
+	webhookSignatureHeader = "X-Event-Signature"
+	defaultWebhookTimeout  = 5 * time.Second
+	defaultWebhookRetries  = 3
+	defaultWebhookBackoff  = 200 * time.Millisecond
+	maxWebhookBackoff      = 10 * time.Second
+)
+
+// WebhookConfig — настройки доставки по HTTP: у webhook-destination или у канала.
+type WebhookConfig struct {
+	Headers    map[string]string `json:"headers,omitempty"`
+	Secret     string            `json:"secret,omitempty"`     // ключ HMAC-SHA256 подписи тела запроса
+	SecretEnv  string            `json:"secret_env,omitempty"` // имя переменной окружения с ключом (вместо secret)
+	TimeoutMs  int               `json:"timeout_ms,omitempty"`
+	MaxRetries *int              `json:"max_retries,omitempty"` // повторы после первой попытки; nil — по умолчанию
+	BackoffMs  int               `json:"backoff_ms,omitempty"`  // задержка перед первым повтором, далее удваивается
+}
+
+func (c *WebhookConfig) validate() error {
+	if c != nil && (c.TimeoutMs < 0 || c.BackoffMs < 0 || (c.MaxRetries != nil && *c.MaxRetries < 0)) {
+		return fmt.Errorf("webhook timeout, backoff and retries must not be negative")
+	}
+	return nil
+}
+
+func (c *WebhookConfig) timeout() time.Duration {
+	if c == nil || c.TimeoutMs <= 0 {
+		return defaultWebhookTimeout
+	}
+	return time.Duration(c.TimeoutMs) * time.Millisecond
+}
+
+func (c *WebhookConfig) retries() int {
+	if c == nil || c.MaxRetries == nil {
+		return defaultWebhookRetries
+	}
+	return *c.MaxRetries
+}
+
+func (c *WebhookConfig) backoff(attempt int) time.Duration {
+	d := defaultWebhookBackoff
+	if c != nil && c.BackoffMs > 0 {
+		d = time.Duration(c.BackoffMs) * time.Millisecond
+	}
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= maxWebhookBackoff {
+			return maxWebhookBackoff
+		}
+	}
+	return d
+}
+
+func (c *WebhookConfig) secret() string {
+	if c == nil {
+		return ""
+	}
+	if c.SecretEnv != "" {
+		return os.Getenv(c.SecretEnv)
+	}
+	return c.Secret
+}
+
+// WebhookPublisher отправляет события POST-запросом на URL из endpoint канала.
+// 5xx, 408, 429 и сетевые ошибки повторяются с экспоненциальной задержкой (408 и 429 —
+// не раньше Retry-After, но не дольше maxWebhookBackoff), остальные 4xx — постоянный отказ.
+type WebhookPublisher struct {
+	registry *EventRegistry
+	client   *http.Client
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+func NewWebhookPublisher(registry *EventRegistry) *WebhookPublisher {
+	return &WebhookPublisher{
+		registry: registry,
+		client:   &http.Client{},
+		sleep:    sleepContext,
+	}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event *domain.Event) error {
+	url, _, err := p.registry.ResolveChannel(event.Type)
+	if err != nil {
+		return fmt.Errorf("failed to resolve channel for event type %s: %w", event.Type, err)
+	}
+	return p.PublishTo(ctx, url, event)
+}
+
+func (p *WebhookPublisher) PublishTo(ctx context.Context, url string, event *domain.Event) error {
+	info, _ := p.registry.GetChannel(event.Type)
+	cfg := info.webhookConfig(url)
+
+	body, err := json.Marshal(domain.NewEventJSON(event))
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	attempts := cfg.retries() + 1
+	for attempt := 1; ; attempt++ {
+		var retryAfter time.Duration
+		retryAfter, err = p.send(ctx, url, body, event, cfg)
+		if err == nil {
+			log.Printf("🌐 Event %s delivered to webhook %s", event.Type, url)
+			return nil
+		}
+		if errors.Is(err, ErrPermanentDelivery) || attempt >= attempts {
+			break
+		}
+		delay := max(cfg.backoff(attempt), min(retryAfter, maxWebhookBackoff))
+		log.Printf("webhook delivery of event %s to %s failed (attempt %d/%d), retry in %s: %v", event.ID, url, attempt, attempts, delay, err)
+		if err := p.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("failed to deliver event %s to webhook %s: %w", event.ID, url, err)
+}
+
+// send выполняет одну попытку доставки. retryAfter — задержка из Retry-After
+// временного отказа (408, 429), если получатель ее указал.
+func (p *WebhookPublisher) send(ctx context.Context, url string, body []byte, event *domain.Event, cfg *WebhookConfig) (retryAfter time.Duration, err error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid webhook request: %v", ErrPermanentDelivery, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", event.ID)
+	req.Header.Set("X-Event-Type", event.Type)
+	if cfg != nil {
+		for k, v := range cfg.Headers {
+			req.Header.Set(k, v)
+		}
+	}
+	if secret := cfg.secret(); secret != "" {
+		req.Header.Set(webhookSignatureHeader, SignWebhookBody(secret, body))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Дочитываем тело, чтобы соединение вернулось в пул
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests:
+		return parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return 0, fmt.Errorf("%w: status %d: %s", ErrPermanentDelivery, resp.StatusCode, bytes.TrimSpace(snippet))
+	default:
+		return 0, fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+}
+
+// parseRetryAfter разбирает Retry-After: число секунд или HTTP-дату. Пустое
+// или некорректное значение — 0.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0)
+	}
+	return 0
+}
+
+// SignWebhookBody возвращает значение заголовка X-Event-Signature: "sha256=" и
+// hex HMAC-SHA256 тела запроса. Получатель проверяет подпись тем же секретом.
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
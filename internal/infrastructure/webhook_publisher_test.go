@@ -0,0 +1,215 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookPublisher_SignsAndSendsHeaders(t *testing.T) {
+	var gotSignature, gotToken, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotSignature = r.Header.Get("X-Event-Signature")
+		gotToken = r.Header.Get("X-Token")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	publisher := setupWebhookPublisher(t, server.URL, `{"headers": {"X-Token": "abc"}, "secret": "s3cret"}`)
+	assertNoError(t, publisher.Publish(context.Background(), createOrderEvent("order-1", time.Now())))
+
+	if gotToken != "abc" {
+		t.Errorf("expected configured header, got %q", gotToken)
+	}
+	if want := SignWebhookBody("s3cret", []byte(gotBody)); gotSignature != want {
+		t.Errorf("expected signature %s, got %s", want, gotSignature)
+	}
+}
+
+func TestWebhookPublisher_UsesDestinationConfig(t *testing.T) {
+	tokens := make(map[string]string)
+	var mu sync.Mutex
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			tokens[name] = r.Header.Get("X-Token")
+			mu.Unlock()
+			w.WriteHeader(http.StatusAccepted)
+		}))
+	}
+	billing, audit, legacy := newServer("billing"), newServer("audit"), newServer("legacy")
+	defer billing.Close()
+	defer audit.Close()
+	defer legacy.Close()
+
+	registry := createTestRegistryFromConfig(t, fmt.Sprintf(`{
+		"OrderStatusEvent": {"schema": "order", "webhook": {"headers": {"X-Token": "channel"}}, "destinations": [
+			{"type": "webhook", "endpoint": %q, "webhook": {"headers": {"X-Token": "billing"}}},
+			{"type": "webhook", "endpoint": %q, "webhook": {"headers": {"X-Token": "audit"}}},
+			{"type": "webhook", "endpoint": %q}
+		]}
+	}`, billing.URL, audit.URL, legacy.URL))
+	publisher := NewWebhookPublisher(registry)
+	event := createOrderEvent("order-1", time.Now())
+	for _, url := range []string{billing.URL, audit.URL, legacy.URL} {
+		assertNoError(t, publisher.PublishTo(context.Background(), url, event))
+	}
+
+	expected := map[string]string{"billing": "billing", "audit": "audit", "legacy": "channel"}
+	for name, want := range expected {
+		if tokens[name] != want {
+			t.Errorf("%s: expected X-Token %q, got %q", name, want, tokens[name])
+		}
+	}
+}
+
+func TestWebhookPublisher_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	publisher := setupWebhookPublisher(t, server.URL, `{"max_retries": 3}`)
+	var delays []time.Duration
+	publisher.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	assertNoError(t, publisher.Publish(context.Background(), createOrderEvent("order-1", time.Now())))
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls.Load())
+	}
+	if len(delays) != 2 || delays[1] != 2*delays[0] {
+		t.Errorf("expected exponential backoff between attempts, got %v", delays)
+	}
+}
+
+func TestWebhookPublisher_ClientErrorIsPermanent(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "bad payload", http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	publisher := setupWebhookPublisher(t, server.URL, `{"max_retries": 3}`)
+	err := publisher.Publish(context.Background(), createOrderEvent("order-1", time.Now()))
+	if !errors.Is(err, ErrPermanentDelivery) {
+		t.Fatalf("expected ErrPermanentDelivery, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected no retries for 4xx, got %d attempts", calls.Load())
+	}
+}
+
+func TestWebhookPublisher_RetriesTooManyRequestsAfterRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	publisher := setupWebhookPublisher(t, server.URL, `{"max_retries": 3, "backoff_ms": 100}`)
+	var delays []time.Duration
+	publisher.sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	assertNoError(t, publisher.Publish(context.Background(), createOrderEvent("order-1", time.Now())))
+	if calls.Load() != 2 {
+		t.Errorf("expected 429 to be retried, got %d attempts", calls.Load())
+	}
+	if len(delays) != 1 || delays[0] != 3*time.Second {
+		t.Errorf("expected retry after the Retry-After delay of 3s, got %v", delays)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"5":                             5 * time.Second,
+		"-1":                            0,
+		"soon":                          0,
+		"Tue, 01 Jul 2025 12:00:30 GMT": 30 * time.Second,
+		"Tue, 01 Jul 2025 11:00:00 GMT": 0,
+	}
+	for value, want := range cases {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestWebhookPublisher_GivesUpAfterRetriesAndTimeouts(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	publisher := setupWebhookPublisher(t, server.URL, `{"timeout_ms": 10, "max_retries": 2}`)
+	err := publisher.Publish(context.Background(), createOrderEvent("order-1", time.Now()))
+	if err == nil || errors.Is(err, ErrPermanentDelivery) {
+		t.Fatalf("expected transient failure, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestWebhookPublisher_StopsRetryingWhenContextCanceled(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	publisher := setupWebhookPublisher(t, server.URL, `{"max_retries": 5, "backoff_ms": 1000}`)
+	publisher.sleep = sleepContext
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := publisher.Publish(ctx, createOrderEvent("order-1", time.Now()))
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected no retries after the deadline, got %d attempts", calls.Load())
+	}
+}
+
+// === Test Helpers ===
+
+func setupWebhookPublisher(t *testing.T, url, webhookConfig string) *WebhookPublisher {
+	registry := createTestRegistryFromConfig(t, fmt.Sprintf(`{
+		"OrderStatusEvent": {"type": "webhook", "endpoint": %q, "schema": "order", "webhook": %s}
+	}`, url, webhookConfig))
+	publisher := NewWebhookPublisher(registry)
+	publisher.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return publisher
+}
@@ -2,30 +2,32 @@ package iface
 
 import (
 	"encoding/json"
+	"errors"
+	"event-system/internal/domain"
 	"event-system/internal/infrastructure"
+	"io"
 	"net/http"
+	"strconv"
 )
 
 type AdminHandler struct {
 	Registry *infrastructure.EventRegistry
+	Reloader *infrastructure.ConfigReloader
+	Channels *infrastructure.ChannelAdmin
 }
 
-func NewAdminHandler(reg *infrastructure.EventRegistry) *AdminHandler {
-	return &AdminHandler{Registry: reg}
+func NewAdminHandler(reg *infrastructure.EventRegistry, reloader *infrastructure.ConfigReloader, channels *infrastructure.ChannelAdmin) *AdminHandler {
+	return &AdminHandler{Registry: reg, Reloader: reloader, Channels: channels}
 }
 
-// POST /admin/reload-channels
+// POST /admin/reload-channels?force=true
+// Каналы и схемы перезагружаются вместе; в ответе — diff изменений.
 func (h *AdminHandler) ReloadChannels(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	if err := h.Registry.Reload(); err != nil {
-		http.Error(w, "reload failed: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("reloaded"))
+	h.reload(w, r)
 }
 
 // GET /admin/channels
@@ -34,3 +36,81 @@ func (h *AdminHandler) GetChannels(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(channels)
 }
+
+// POST /admin/reload-schemas?force=true
+// То же, что reload-channels: схемы без каналов не перезагружаются.
+func (h *AdminHandler) ReloadSchemas(w http.ResponseWriter, r *http.Request) {
+	h.reload(w, r)
+}
+
+// reload применяет новую конфигурацию. Breaking изменения схем отклоняются с 409,
+// если не передан force=true; ошибки в channels.json или схемах — 422.
+func (h *AdminHandler) reload(w http.ResponseWriter, r *http.Request) {
+	report, err := h.Reloader.Reload("admin", r.URL.Query().Get("force") == "true")
+	if err != nil {
+		var compatErr *domain.SchemaCompatibilityError
+		if errors.As(err, &compatErr) {
+			writeJSON(w, http.StatusConflict, map[string]interface{}{"error": err.Error(), "report": report})
+			return
+		}
+		if errors.Is(err, infrastructure.ErrInvalidConfig) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		http.Error(w, "reload failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// GET /admin/reload-events
+// Последние перезагрузки конфигурации (ручные и через watcher), включая неудачные.
+func (h *AdminHandler) GetReloadEvents(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.Reloader.Events())
+}
+
+// POST /admin/schemas/{name}/check?version=1
+// Сравнивает присланную схему с загруженной и возвращает отчет о совместимости, ничего не меняя.
+func (h *AdminHandler) CheckSchema(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	version := 1
+	if v := r.URL.Query().Get("version"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 1 {
+			http.Error(w, "invalid version: "+v, http.StatusBadRequest)
+			return
+		}
+		version = parsed
+	}
+
+	schemas := h.Reloader.Validator.Schemas()
+	current, ok := schemas.Document(name, version)
+	if !ok {
+		http.Error(w, "schema "+name+" version "+strconv.Itoa(version)+" not found", http.StatusNotFound)
+		return
+	}
+	if format, _ := schemas.Format(name); format != domain.PayloadFormatJSON {
+		http.Error(w, "compatibility check is supported for JSON Schema only, schema "+name+" is "+string(format), http.StatusBadRequest)
+		return
+	}
+
+	proposed, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	report, err := domain.CheckSchemaCompatibility(current, proposed)
+	if err != nil {
+		http.Error(w, "Invalid schema: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,111 @@
+package iface
+
+import (
+	"encoding/json"
+	"errors"
+	"event-system/internal/infrastructure"
+	"net/http"
+)
+
+// GET /admin/channels/{name}
+func (h *AdminHandler) GetChannel(w http.ResponseWriter, r *http.Request) {
+	info, ok := h.Registry.GetChannel(r.PathValue("name"))
+	if !ok {
+		http.Error(w, "channel not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// POST /admin/channels/{name}
+func (h *AdminHandler) CreateChannel(w http.ResponseWriter, r *http.Request) {
+	info, ok := decodeChannelInfo(w, r)
+	if !ok {
+		return
+	}
+	if err := h.Channels.Create(adminActor(r), r.PathValue("name"), info); err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	h.writeStoredChannel(w, http.StatusCreated, r.PathValue("name"))
+}
+
+// PUT /admin/channels/{name}
+func (h *AdminHandler) UpdateChannel(w http.ResponseWriter, r *http.Request) {
+	info, ok := decodeChannelInfo(w, r)
+	if !ok {
+		return
+	}
+	if err := h.Channels.Update(adminActor(r), r.PathValue("name"), info); err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	h.writeStoredChannel(w, http.StatusOK, r.PathValue("name"))
+}
+
+// DELETE /admin/channels/{name}
+func (h *AdminHandler) DeleteChannel(w http.ResponseWriter, r *http.Request) {
+	if err := h.Channels.Delete(adminActor(r), r.PathValue("name")); err != nil {
+		writeChannelError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /admin/audit
+func (h *AdminHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.Channels.AuditEntries()
+	if err != nil {
+		http.Error(w, "failed to read audit log: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// writeStoredChannel отвечает конфигурацией канала в том виде, в каком ее сохранил
+// registry: с нормализованными типом, endpoint и значениями по умолчанию.
+func (h *AdminHandler) writeStoredChannel(w http.ResponseWriter, status int, name string) {
+	info, ok := h.Registry.GetChannel(name)
+	if !ok {
+		// Канал удалили между изменением и чтением
+		http.Error(w, "channel not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, status, info)
+}
+
+func decodeChannelInfo(w http.ResponseWriter, r *http.Request) (infrastructure.EventChannelInfo, bool) {
+	var info infrastructure.EventChannelInfo
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&info); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return info, false
+	}
+	return info, true
+}
+
+func writeChannelError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, infrastructure.ErrChannelNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, infrastructure.ErrChannelExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, infrastructure.ErrInvalidChannel):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		http.Error(w, "failed to update channels: "+err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// adminActor определяет, кто выполняет изменение: заголовок X-Admin-User,
+// имя пользователя Basic auth или адрес клиента.
+func adminActor(r *http.Request) string {
+	if user := r.Header.Get("X-Admin-User"); user != "" {
+		return user
+	}
+	if user, _, ok := r.BasicAuth(); ok && user != "" {
+		return user
+	}
+	return r.RemoteAddr
+}
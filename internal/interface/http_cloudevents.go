@@ -0,0 +1,38 @@
+package iface
+
+import (
+	"encoding/json"
+	"event-system/internal/domain"
+	"event-system/internal/infrastructure"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// cloudEventsHeaderPrefix — префикс HTTP-заголовков атрибутов CloudEvent в binary-режиме.
+const cloudEventsHeaderPrefix = "Ce-"
+
+// decodeEventRequest разбирает тело запроса: CloudEvent в structured-режиме
+// (Content-Type: application/cloudevents+json), в binary-режиме (заголовки Ce-*)
+// или событие в собственном формате.
+func decodeEventRequest(r *http.Request, body []byte) (*domain.Event, error) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == infrastructure.CloudEventsContentType {
+		return infrastructure.DecodeStructuredCloudEvent(body)
+	}
+	if r.Header.Get(cloudEventsHeaderPrefix+"Specversion") != "" {
+		attrs := make(map[string]string)
+		for name, values := range r.Header {
+			if attr, ok := strings.CutPrefix(name, cloudEventsHeaderPrefix); ok && len(values) > 0 {
+				attrs[strings.ToLower(attr)] = values[0]
+			}
+		}
+		return infrastructure.DecodeBinaryCloudEvent(attrs, r.Header.Get("Content-Type"), body)
+	}
+
+	var event domain.EventJSON
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return event.Event(), nil
+}
@@ -0,0 +1,103 @@
+package iface
+
+import (
+	"encoding/json"
+	"errors"
+	"event-system/internal/application"
+	"event-system/internal/domain"
+	"event-system/internal/infrastructure"
+	"io"
+	"net/http"
+)
+
+type DeadLetterHandler struct {
+	Store   *infrastructure.SQLiteDeadLetterStore
+	Service *application.EventService
+}
+
+func NewDeadLetterHandler(store *infrastructure.SQLiteDeadLetterStore, service *application.EventService) *DeadLetterHandler {
+	return &DeadLetterHandler{Store: store, Service: service}
+}
+
+// GET /admin/dead-letters?destination=orders-dlq&all=true
+func (h *DeadLetterHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	letters, err := h.Store.List(query.Get("destination"), query.Get("all") == "true")
+	if err != nil {
+		writeProblem(w, r, newProblem(http.StatusInternalServerError, "internal-error", "Failed to list dead letters", err.Error()))
+		return
+	}
+	if letters == nil {
+		letters = []*domain.DeadLetter{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(letters)
+}
+
+// GET /admin/dead-letters/{id}
+func (h *DeadLetterHandler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	letter, ok := h.loadDeadLetter(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(letter)
+}
+
+// POST /admin/dead-letters/{id}/replay
+// Пустое тело — отправить сохраненное событие как есть; иначе тело — исправленное событие.
+// Окно timestamp канала при отыгрывании не проверяется. Ошибки — application/problem+json
+// с теми же статусами и кодами, что и у POST /event.
+func (h *DeadLetterHandler) ReplayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	letter, ok := h.loadDeadLetter(w, r)
+	if !ok {
+		return
+	}
+	if letter.ReplayedAt != nil {
+		writeProblem(w, r, newProblem(http.StatusConflict, "already-replayed", "Dead letter already replayed", ""))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeProblem(w, r, newProblem(http.StatusBadRequest, "invalid-request", "Failed to read request body", err.Error()))
+		return
+	}
+	defer r.Body.Close()
+
+	event := letter.Event
+	if len(body) > 0 {
+		var doc domain.EventJSON
+		if err := json.Unmarshal(body, &doc); err != nil {
+			writeProblem(w, r, newProblem(http.StatusBadRequest, "invalid-event", "Invalid event", "invalid JSON: "+err.Error()))
+			return
+		}
+		event = doc.Event()
+	}
+
+	// Отыгрываемое событие старше окна timestamp канала по определению
+	if err := h.Service.ProcessEvent(domain.WithoutTimestampWindow(r.Context()), event); err != nil {
+		writeProblem(w, r, eventProblem(err))
+		return
+	}
+
+	if err := h.Store.MarkReplayed(letter.ID); err != nil {
+		writeProblem(w, r, newProblem(http.StatusInternalServerError, "internal-error", "Event replayed but dead letter not updated", err.Error()))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("replayed"))
+}
+
+func (h *DeadLetterHandler) loadDeadLetter(w http.ResponseWriter, r *http.Request) (*domain.DeadLetter, bool) {
+	letter, err := h.Store.Get(r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, infrastructure.ErrDeadLetterNotFound) {
+			writeProblem(w, r, newProblem(http.StatusNotFound, "dead-letter-not-found", "Dead letter not found", err.Error()))
+		} else {
+			writeProblem(w, r, newProblem(http.StatusInternalServerError, "internal-error", "Failed to load dead letter", err.Error()))
+		}
+		return nil, false
+	}
+	return letter, true
+}
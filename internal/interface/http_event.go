@@ -1,16 +1,17 @@
 package iface
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"event-system/internal/application"
-	"event-system/internal/domain"
 	"io"
 	"net/http"
+	"time"
 )
 
 type EventHandler struct {
 	Service *application.EventService
+	Timeout time.Duration // optional: предел обработки одного события; 0 — только контекст запроса
 }
 
 func NewEventHandler(service *application.EventService) *EventHandler {
@@ -19,34 +20,42 @@ func NewEventHandler(service *application.EventService) *EventHandler {
 
 func (h *EventHandler) HandleEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		writeProblem(w, r, newProblem(http.StatusMethodNotAllowed, "method-not-allowed", "Method Not Allowed", ""))
 		return
 	}
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		writeProblem(w, r, newProblem(http.StatusBadRequest, "invalid-request", "Failed to read request body", err.Error()))
 		return
 	}
 	defer r.Body.Close()
 
-	var event domain.Event
-	if err := json.Unmarshal(body, &event); err != nil {
-		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+	event, err := decodeEventRequest(r, body)
+	if err != nil {
+		writeProblem(w, r, newProblem(http.StatusBadRequest, "invalid-event", "Invalid event", err.Error()))
 		return
 	}
 
-	if err := h.Service.ProcessEvent(&event); err != nil {
-		var validationErr *domain.EventValidationError
-		if errors.As(err, &validationErr) {
-			http.Error(w, "Validation error: "+err.Error(), http.StatusBadRequest)
-		} else {
-			// Internal error
-			http.Error(w, "Failed to process event: "+err.Error(), http.StatusInternalServerError)
-		}
+	event.Metadata = event.Metadata.Merge(metadataFromRequest(r))
+
+	ctx := r.Context()
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+
+	receipt, err := h.Service.Submit(ctx, event, r.Header.Get("Idempotency-Key"))
+	if err != nil {
+		writeProblem(w, r, eventProblem(err))
 		return
 	}
 
+	if receipt.Duplicate {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("ok"))
+	json.NewEncoder(w).Encode(receipt)
 }
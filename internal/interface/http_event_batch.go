@@ -0,0 +1,185 @@
+package iface
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"event-system/internal/application"
+	"event-system/internal/domain"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const defaultMaxBatchSize = 500
+
+// BatchEventHandler принимает пакет событий: JSON-массив или NDJSON (одно событие на строку).
+type BatchEventHandler struct {
+	Service      *application.EventService
+	MaxBatchSize int           // 0 — defaultMaxBatchSize
+	Timeout      time.Duration // optional: предел обработки всего пакета
+}
+
+func NewBatchEventHandler(service *application.EventService) *BatchEventHandler {
+	return &BatchEventHandler{Service: service}
+}
+
+// BatchResponse — ответ на пакет: счетчики и результат по каждому событию.
+type BatchResponse struct {
+	Mode     application.BatchMode         `json:"mode"`
+	Accepted int                           `json:"accepted"`
+	Rejected int                           `json:"rejected"`
+	Failed   int                           `json:"failed"`
+	Skipped  int                           `json:"skipped"`
+	Results  []application.BatchItemResult `json:"results"`
+}
+
+// POST /events/batch?mode=atomic|partial
+// 200 — все события приняты, 207 — приняты не все, 422 — не принято ни одно
+// (или atomic без outbox), 503 — ни одно не принято и часть не удалось опубликовать.
+func (h *BatchEventHandler) HandleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeProblem(w, r, newProblem(http.StatusMethodNotAllowed, "method-not-allowed", "Method Not Allowed", ""))
+		return
+	}
+	mode, err := application.ParseBatchMode(r.URL.Query().Get("mode"))
+	if err != nil {
+		writeProblem(w, r, newProblem(http.StatusBadRequest, "invalid-request", "Invalid batch mode", err.Error()))
+		return
+	}
+	if mode == application.BatchModeAtomic && !h.Service.SupportsAtomicBatch() {
+		writeProblem(w, r, eventProblem(application.ErrAtomicBatchUnsupported))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeProblem(w, r, newProblem(http.StatusBadRequest, "invalid-request", "Failed to read request body", err.Error()))
+		return
+	}
+	defer r.Body.Close()
+
+	items, err := splitBatch(body)
+	if err != nil {
+		writeProblem(w, r, newProblem(http.StatusBadRequest, "invalid-batch", "Invalid batch", err.Error()))
+		return
+	}
+	if len(items) == 0 {
+		writeProblem(w, r, newProblem(http.StatusBadRequest, "invalid-batch", "Invalid batch", "no events"))
+		return
+	}
+	if max := h.maxBatchSize(); len(items) > max {
+		writeProblem(w, r, newProblem(http.StatusRequestEntityTooLarge, "batch-too-large", "Batch too large", fmt.Sprintf("%d events, limit %d", len(items), max)))
+		return
+	}
+
+	ctx := r.Context()
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+
+	results := h.process(ctx, items, mode, metadataFromRequest(r))
+	writeBatchResponse(w, mode, results)
+}
+
+// process разбирает события; неразобранные отклоняются сразу, а в режиме atomic
+// отклоняют и весь пакет. Метаданные из заголовков запроса применяются ко всем событиям.
+func (h *BatchEventHandler) process(ctx context.Context, items []json.RawMessage, mode application.BatchMode, metadata *domain.EventMetadata) []application.BatchItemResult {
+	results := make([]application.BatchItemResult, len(items))
+	var events []*domain.Event
+	var indexes []int
+	for i, item := range items {
+		var doc domain.EventJSON
+		if err := json.Unmarshal(item, &doc); err != nil {
+			results[i] = application.BatchItemResult{Index: i, Status: application.BatchItemRejected, Error: "invalid JSON: " + err.Error(), Code: "invalid-event"}
+			continue
+		}
+		event := doc.Event()
+		event.Metadata = event.Metadata.Merge(metadata)
+		results[i] = application.BatchItemResult{Index: i, EventID: event.ID}
+		events = append(events, event)
+		indexes = append(indexes, i)
+	}
+
+	if mode == application.BatchModeAtomic && len(events) < len(items) {
+		for _, i := range indexes {
+			results[i].Status = application.BatchItemSkipped
+			results[i].Error = fmt.Sprintf("batch rejected: %d of %d events are not valid JSON", len(items)-len(events), len(items))
+		}
+		return results
+	}
+
+	for n, result := range h.Service.ProcessBatch(ctx, events, mode) {
+		result.Index = indexes[n]
+		if result.Err != nil && result.Status != application.BatchItemSkipped {
+			result.Code = eventProblem(result.Err).Code
+		}
+		results[indexes[n]] = result
+	}
+	return results
+}
+
+func (h *BatchEventHandler) maxBatchSize() int {
+	if h.MaxBatchSize > 0 {
+		return h.MaxBatchSize
+	}
+	return defaultMaxBatchSize
+}
+
+// splitBatch делит тело на события: JSON-массив, если тело начинается с '[',
+// иначе NDJSON. Пустые строки NDJSON пропускаются.
+func splitBatch(body []byte) ([]json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+
+	var items []json.RawMessage
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(trimmed)+1)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, json.RawMessage(bytes.Clone(line)))
+	}
+	return items, scanner.Err()
+}
+
+func writeBatchResponse(w http.ResponseWriter, mode application.BatchMode, results []application.BatchItemResult) {
+	resp := BatchResponse{Mode: mode, Results: results}
+	for _, r := range results {
+		switch r.Status {
+		case application.BatchItemAccepted:
+			resp.Accepted++
+		case application.BatchItemRejected:
+			resp.Rejected++
+		case application.BatchItemFailed:
+			resp.Failed++
+		case application.BatchItemSkipped:
+			resp.Skipped++
+		}
+	}
+
+	status := http.StatusOK
+	switch {
+	case resp.Accepted == 0 && resp.Failed > 0:
+		status = http.StatusServiceUnavailable
+	case resp.Accepted == 0:
+		status = http.StatusUnprocessableEntity
+	case resp.Accepted < len(results):
+		status = http.StatusMultiStatus
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,45 @@
+package iface
+
+import (
+	"event-system/internal/domain"
+	"net/http"
+	"strings"
+)
+
+// metadataHeaderPrefix — префикс HTTP-заголовков со свободными метаданными:
+// X-Meta-Request-Origin: web -> headers["request-origin"] = "web".
+const metadataHeaderPrefix = "X-Meta-"
+
+// metadataRequestHeaders — HTTP-заголовки стандартных полей метаданных.
+var metadataRequestHeaders = map[string]string{
+	"X-Correlation-Id": "correlation-id",
+	"X-Causation-Id":   "causation-id",
+	"X-Source-Service": "source",
+	"X-Tenant-Id":      "tenant",
+	"X-Actor-Id":       "actor",
+}
+
+// metadataFromRequest собирает метаданные из заголовков запроса. Они дополняют
+// поле metadata в теле события, но не перекрывают его.
+func metadataFromRequest(r *http.Request) *domain.EventMetadata {
+	m := &domain.EventMetadata{}
+	for header, field := range metadataRequestHeaders {
+		if value := r.Header.Get(header); value != "" {
+			m.SetField(field, value)
+		}
+	}
+	for header, values := range r.Header {
+		name, ok := strings.CutPrefix(header, metadataHeaderPrefix)
+		if !ok || name == "" || len(values) == 0 {
+			continue
+		}
+		if m.Headers == nil {
+			m.Headers = make(map[string]string)
+		}
+		m.Headers[strings.ToLower(name)] = values[0]
+	}
+	if m.IsEmpty() {
+		return nil
+	}
+	return m
+}
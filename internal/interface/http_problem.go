@@ -0,0 +1,81 @@
+package iface
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"event-system/internal/application"
+	"event-system/internal/domain"
+	"net/http"
+)
+
+// ProblemContentType — тип ответа об ошибке по RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix — пространство имен URI типов ошибок; тип не резолвится, а служит
+// стабильным идентификатором для клиентов. Последний сегмент типа — код ошибки.
+const problemTypePrefix = "urn:event-system:problem:"
+
+// Problem — ответ об ошибке по RFC 7807. Code — стабильный машиночитаемый код
+// (совпадает с окончанием Type), Violations — нарушения по полям события,
+// заполняются для ошибок валидации.
+type Problem struct {
+	Type       string             `json:"type"`
+	Title      string             `json:"title"`
+	Status     int                `json:"status"`
+	Detail     string             `json:"detail,omitempty"`
+	Instance   string             `json:"instance,omitempty"`
+	Code       string             `json:"code"`
+	Violations []domain.Violation `json:"violations,omitempty"`
+}
+
+func newProblem(status int, code, title, detail string) *Problem {
+	return &Problem{Type: problemTypePrefix + code, Title: title, Status: status, Detail: detail, Code: code}
+}
+
+// eventProblem переводит ошибку приема события в Problem. Ошибки клиента проверяются
+// раньше ошибок публикации: отсутствующий ключ партиции может прийти и от публикации
+// события, принятого до изменения partition_key канала.
+func eventProblem(err error) *Problem {
+	var (
+		validationErr *domain.EventValidationError
+		publishErr    *domain.PublishError
+	)
+	switch {
+	case errors.As(err, &validationErr):
+		p := newProblem(http.StatusBadRequest, "validation-failed", "Event validation failed", validationErr.Reason)
+		p.Violations = validationErr.Violations
+		return p
+	case errors.Is(err, domain.ErrUnknownEventType):
+		return newProblem(http.StatusNotFound, "unknown-event-type", "Unknown event type", err.Error())
+	case errors.Is(err, domain.ErrSchemaNotFound):
+		return newProblem(http.StatusUnprocessableEntity, "schema-not-found", "Schema not found", err.Error())
+	case errors.Is(err, domain.ErrDuplicateInProgress):
+		return newProblem(http.StatusConflict, "duplicate-in-progress", "Event is already being processed", err.Error())
+	case errors.Is(err, domain.ErrIdempotencyKeyReused):
+		return newProblem(http.StatusUnprocessableEntity, "idempotency-key-reused", "Idempotency key reused", err.Error())
+	case errors.Is(err, application.ErrAtomicBatchUnsupported):
+		// Клиенту нужно знать, как включить outbox, а не только что его нет
+		return newProblem(http.StatusUnprocessableEntity, "atomic-unsupported", "Atomic batches are not supported",
+			err.Error()+": start the service with EVENT_OUTBOX_DSN set, or send the batch with mode=partial")
+	case errors.Is(err, context.DeadlineExceeded):
+		return newProblem(http.StatusGatewayTimeout, "timeout", "Event processing timed out", err.Error())
+	case errors.Is(err, domain.ErrMissingPartitionKey):
+		return newProblem(http.StatusBadRequest, "missing-partition-key", "Invalid event", err.Error())
+	case errors.As(err, &publishErr) && publishErr.Permanent:
+		return newProblem(http.StatusBadGateway, "publish-rejected", "Event rejected by destination", err.Error())
+	case errors.As(err, &publishErr):
+		return newProblem(http.StatusServiceUnavailable, "publish-unavailable", "Event could not be published", err.Error())
+	}
+	return newProblem(http.StatusInternalServerError, "internal-error", "Failed to process event", err.Error())
+}
+
+// writeProblem отвечает документом application/problem+json; instance — путь запроса.
+func writeProblem(w http.ResponseWriter, r *http.Request, p *Problem) {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}